@@ -0,0 +1,219 @@
+package board
+
+import (
+	"math/bits"
+
+	"ChessEngine/globals"
+)
+
+// Precomputed tables of the cells attacked from each position by the pieces
+// that always move the same (knights, kings and pawns). Pawn attacks depend on
+// the color of the pawn
+var (
+	knightAttacks [64]table
+	kingAttacks   [64]table
+	pawnAttacks   [2][64]table
+)
+
+// Magic bitboards of the sliding pieces. Queens use both
+var (
+	rookMagics   [64]magic
+	bishopMagics [64]magic
+)
+
+// A magic is what's needed to find the cells a sliding piece attacks from a
+// position without going through its rays one cell at a time.
+//
+// Only the pieces in the cells of the mask (the cells of the rays, without the
+// edges of the board) can block the piece. Multiplying those pieces by the magic
+// number gathers them in the highest bits of the result, which is then used as
+// the index of the attacks table, which was filled in advance for every
+// possible combination of blocking pieces
+type magic struct {
+	mask    table
+	number  uint64
+	shift   uint
+	attacks []table
+}
+
+// Magic numbers of the rooks and the bishops for each position. They were found
+// by trying random numbers with few ones until one of them gave a different
+// index to every combination of blocking pieces that attacks different cells
+var rookMagicNumbers = [64]uint64{
+	0x2000048044003102, 0x0100820810811014, 0x0015000400080201, 0x0101000408000211,
+	0x0001100020050009, 0x2400200113020841, 0x01c0802100104001, 0x4000800020104101,
+	0x0000a10084004200, 0x0000100802010400, 0x0802800401020080, 0x4010080080040080,
+	0x1000100021000900, 0x4014104820010100, 0x1002860440270600, 0x4520800040002280,
+	0x0010215e89060004, 0x10820801022c0010, 0x0002000400028080, 0x200b000488010010,
+	0xc821001002230008, 0x0380200041010010, 0x4010012000424000, 0x0400308040008008,
+	0x00400c0842000081, 0x2082080104001002, 0x2002002004040010, 0x20040e0006000aa0,
+	0x8000100101000820, 0x1060084101001022, 0x0108600840c01003, 0x3400400020800080,
+	0x0000049200244104, 0x0101002100240200, 0x0406000200081004, 0x3051000500120800,
+	0x0427100680080080, 0x0000100080200082, 0x0000200080400080, 0xd100400a800182a0,
+	0x0802020008806104, 0x1000010100040200, 0x0022008004000280, 0x0204008008000480,
+	0x0020210009001000, 0x0c20008010002080, 0x0490024010442001, 0x0010808001e44000,
+	0x200200041c810046, 0x2c0c000241840810, 0x0402000200080410, 0x2422800800240280,
+	0x0000808008001000, 0x0000801000802008, 0x0002004200288500, 0x0040800040008028,
+	0x0200020080402401, 0x0080010002000080, 0x0100020400080100, 0x0600082010242200,
+	0x4200084200200510, 0x020010200a008041, 0x00c0200040009002, 0x2080001222400880,
+}
+
+var bishopMagicNumbers = [64]uint64{
+	0x2034010602120200, 0x0460041004083092, 0x0001504011022084, 0x8000800004350401,
+	0x1400002840420202, 0x0040500201008880, 0x1010208a01500202, 0x5008808811212008,
+	0x0002100409204021, 0x8004081024208050, 0x00e8402811010400, 0x1242002020410012,
+	0x0000001842088149, 0x101102208c103215, 0x0140440084b04220, 0x5002011002100b08,
+	0x42a2021446000300, 0x0420089089000884, 0x1801013001000080, 0x0415601208800400,
+	0x2200010411082801, 0x440200140c004200, 0x0800809010040918, 0x104101a010182000,
+	0x8080808201031905, 0x0222041041011820, 0x1010010202024241, 0x4840004010010100,
+	0x0021200800130050, 0x0400202800100b82, 0x042808044002c400, 0x9002024000101044,
+	0x410c009001009080, 0x0004408414088400, 0x004409004420a000, 0x0012002002008046,
+	0x8108080006820003, 0x208c02004c08009c, 0x0150100004c90241, 0x8035100004109000,
+	0x0008800240484800, 0x0001006400886400, 0x0802008100410450, 0x20420104220100c5,
+	0x020c004202120000, 0x00b00a0800404888, 0x8248400208491410, 0x1141000404880228,
+	0x0140020200824800, 0x00005100b0100800, 0x8000622210408ac0, 0x1010121210020080,
+	0x0000022082000001, 0x0210c1022200420c, 0x0411220881040880, 0x0002491810040048,
+	0x810100210402c019, 0x0100808848408110, 0x0003042026320800, 0x0002021005000000,
+	0x0808048314440010, 0x0008081100260027, 0x0082240104090000, 0x0c30411204005200,
+}
+
+// Returns the cells attacked from the position of the magic with the pieces
+// of the table occupied in the board
+func (m *magic) getAttacks(occupied table) table {
+	return m.attacks[(uint64(occupied&m.mask)*m.number)>>m.shift]
+}
+
+func init() {
+	for pos := 0; pos < globals.TableDim*globals.TableDim; pos++ {
+		knightAttacks[pos] = leaperAttacks(KnightMovements, pos)
+		kingAttacks[pos] = leaperAttacks(KingMovements, pos)
+		pawnAttacks[White][pos] = leaperAttacks(WhitePawnMovements, pos)
+		pawnAttacks[Black][pos] = leaperAttacks(BlackPawnMovements, pos)
+		rookMagics[pos] = newMagic(RookMovements, pos, rookMagicNumbers[pos])
+		bishopMagics[pos] = newMagic(BishopMovements, pos, bishopMagicNumbers[pos])
+	}
+}
+
+// Returns the cells a piece with the given movements attacks from the position
+// pos, performing each movement once. Movements that cannot capture (pawns moving
+// forward) are not attacks
+func leaperAttacks(movements []Movement, pos int) (attacks table) {
+	for _, m := range movements {
+		if m.Capture == cannotCapture {
+			continue
+		}
+		if npos, ok := m.apply(pos, 1); ok {
+			attacks.set(npos)
+		}
+	}
+	return
+}
+
+// Returns the cells a sliding piece with the given movements attacks from the
+// position pos, when the cells of the table occupied have pieces. Every ray
+// stops at the first piece found, which is attacked too
+func slidingAttacks(movements []Movement, pos int, occupied table) (attacks table) {
+	for _, m := range movements {
+		for r := 1; r <= m.Limit; r++ {
+			npos, ok := m.apply(pos, r)
+			if !ok {
+				break
+			}
+			attacks.set(npos)
+			if occupied.has(npos) {
+				break
+			}
+		}
+	}
+	return
+}
+
+// Returns the cells of the rays of the sliding piece that can hold a blocking
+// piece. The last cell of each ray is not included, since there is nothing
+// behind it to block
+func slidingMask(movements []Movement, pos int) (mask table) {
+	for _, m := range movements {
+		for r := 1; r <= m.Limit; r++ {
+			// the next cell has to be in the board for this one to block anything
+			if _, ok := m.apply(pos, r+1); !ok {
+				break
+			}
+			npos, _ := m.apply(pos, r)
+			mask.set(npos)
+		}
+	}
+	return
+}
+
+// Returns the magic of the sliding piece with the given movements at the
+// position pos, with its attacks table filled for every combination of blocking
+// pieces. Panics if the magic number gives the same index to two combinations
+// that attack different cells
+func newMagic(movements []Movement, pos int, number uint64) magic {
+	m := magic{mask: slidingMask(movements, pos), number: number}
+	n := bits.OnesCount64(uint64(m.mask))
+	m.shift = uint(64 - n)
+	m.attacks = make([]table, 1<<n)
+	filled := make([]bool, 1<<n)
+	// go through the subsets of the mask with the Carry-Rippler trick
+	for occupied := table(0); ; {
+		idx := (uint64(occupied) * m.number) >> m.shift
+		attacks := slidingAttacks(movements, pos, occupied)
+		if filled[idx] && m.attacks[idx] != attacks {
+			panic("invalid magic number")
+		}
+		m.attacks[idx], filled[idx] = attacks, true
+		occupied = (occupied - m.mask) & m.mask
+		if occupied == 0 {
+			break
+		}
+	}
+	return m
+}
+
+// Returns the cells attacked by a rook at position pos
+func rookAttacks(pos int, occupied table) table {
+	return rookMagics[pos].getAttacks(occupied)
+}
+
+// Returns the cells attacked by a bishop at position pos
+func bishopAttacks(pos int, occupied table) table {
+	return bishopMagics[pos].getAttacks(occupied)
+}
+
+// Returns the cells attacked by a piece of type pt at position pos
+func (board *Board) attacksFrom(pt PieceType, pos int) table {
+	occupied := board.tableCurrentFrame
+	switch pt {
+	case WhitePawn, BlackPawn:
+		return pawnAttacks[pt%2][pos]
+	case WhiteKnight, BlackKnight:
+		return knightAttacks[pos]
+	case WhiteBishop, BlackBishop:
+		return bishopAttacks(pos, occupied)
+	case WhiteRook, BlackRook:
+		return rookAttacks(pos, occupied)
+	case WhiteQueen, BlackQueen:
+		return rookAttacks(pos, occupied) | bishopAttacks(pos, occupied)
+	default:
+		return kingAttacks[pos]
+	}
+}
+
+// Returns true if any piece of color by attacks the cell at position pos. Instead
+// of going through the pieces, it looks from the cell as if it was each type of
+// piece: if it attacks a piece of that same type, that piece attacks the cell
+func (board *Board) isAttacked(pos int, by Color) bool {
+	pieces := &board.pieceTables
+	occupied := board.tableCurrentFrame
+	// piece types of the color by are the white ones plus the color
+	pt := func(white PieceType) PieceType {
+		return white + PieceType(by)
+	}
+	queens := pieces[pt(WhiteQueen)]
+	return pawnAttacks[by.Opponent()][pos]&pieces[pt(WhitePawn)] != 0 ||
+		knightAttacks[pos]&pieces[pt(WhiteKnight)] != 0 ||
+		kingAttacks[pos]&pieces[pt(WhiteKing)] != 0 ||
+		bishopAttacks(pos, occupied)&(pieces[pt(WhiteBishop)]|queens) != 0 ||
+		rookAttacks(pos, occupied)&(pieces[pt(WhiteRook)]|queens) != 0
+}
@@ -2,33 +2,15 @@ package board
 
 import (
 	"errors"
-	"fmt"
-	"image/color"
-	_ "image/png"
-	"log"
-	"math"
-	"os"
+	"math/bits"
 
 	"ChessEngine/globals"
-	"ChessEngine/utils"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-)
-
-const (
-	// Table dimensions (squared)
-	tDimensions = 8
-	// Table inital value
-	tInitValue table = 18446462598732906495
-	// NillValue is all 1's, so its the max value for an uint64
-	tNilValue table = 18446744073709551615
 )
 
 var (
-	images map[PieceType]*ebiten.Image
-
 	ErrNoPieceAtPos = errors.New("no piece at the given position")
+	ErrWrongTurn    = errors.New("it is not the turn of the piece's color")
+	ErrIllegalMove  = errors.New("illegal move")
 )
 
 // A table is a number which represents the cells that have Pieces in it. For
@@ -54,262 +36,275 @@ func (t *table) move(from, to int) {
 	*t = *t | (1 << to)      // put 1 in new position
 }
 
-type coordinate struct {
-	x, y uint
+func (t *table) set(pos int) {
+	pos = globals.TableDim*globals.TableDim - pos - 1
+	*t = *t | (1 << pos) // put 1 in the position
+}
+
+func (t *table) clear(pos int) {
+	pos = globals.TableDim*globals.TableDim - pos - 1
+	*t = *t & (^(1 << pos)) // put 0 in the position
+}
+
+// Returns true if there is a one at position pos of the table
+func (t table) has(pos int) bool {
+	return t&(1<<(globals.TableDim*globals.TableDim-pos-1)) != 0
+}
+
+// Returns the positions that have a one in the table, in increasing order
+func (t table) positions() (positions []int) {
+	for t != 0 {
+		// the leftmost one is the lowest position
+		pos := bits.LeadingZeros64(uint64(t))
+		positions = append(positions, pos)
+		t.clear(pos)
+	}
+	return
 }
 
 type Board struct {
-	// This variable saves the state of every individual piece. Its position, and its
-	// type
-	pieces map[int]*Piece
-	// this saves the state of the pieces. If in between frames, the value of
-	// the table is different, this means the board has changed
-	tableCurrentFrame  table
-	tablePreviousFrame table
-	// this saves the state of the movements table. The movements table is a table
-	// in which we store all possible movements from a piece.
-	// This table is saved whenever the user clicks on a piece
-	availablePositions []int
-	// this saves a state of the clicked events
-	// if in between frames, this two variables are equal, this means
-	// the state of the board has not changed
-	clickedCurrentFrame  bool
-	clickedPreviousFrame bool
-	// This saves the state of the location of the click in {x,y} coordinates
-	// Being the coordinate {0,0} the top left corner of the table
-	clickedAtCurrentFrame  coordinate
-	clickedAtPreviousFrame coordinate
+	// This variable saves the state of every individual piece. There is one table
+	// per type of piece, with the cells the pieces of that type are at
+	pieceTables [12]table
+	// this saves the cells occupied by the pieces of each color
+	colorTables [2]table
+	// this saves the state of the pieces (the cells occupied by any piece)
+	tableCurrentFrame table
+	// color of the player that has to make the next move
+	sideToMove Color
+	// castling moves each player is still allowed to perform
+	castlingRights CastlingRights
+	// cell a pawn can move to by capturing en passant, which is the cell skipped
+	// by a pawn that has just moved two cells. noEnPassant if there is none
+	enPassant int
+	// number of moves since the last capture or pawn move, used for the fifty
+	// move rule
+	halfmoveClock int
+	// number of the current move, which starts at 1 and is incremented after
+	// every black move
+	fullmoveNumber int
+	// Zobrist key of the position, updated with every change of the board
+	hash uint64
+	// position the board was set to, in FEN
+	startFEN string
+	// moves made since the position was set, which can be undone, and moves
+	// undone, which can be redone
+	history   []undoInfo
+	redoMoves []Move
+	// moves made by a search with MakeMove, which are not part of the game
+	searchHistory []undoInfo
 }
 
 func (board *Board) GetTableCurrentFrame() table {
 	return board.tableCurrentFrame
 }
 
-func (board *Board) GetClickedAtCurrent() (x, y int) {
-	return int(board.clickedAtCurrentFrame.x), int(board.clickedAtCurrentFrame.y)
+// Returns the color of the player that has to make the next move
+func (board *Board) GetSideToMove() Color {
+	return board.sideToMove
+}
+
+// Returns the number of moves since the last capture or pawn move
+func (board *Board) GetHalfmoveClock() int {
+	return board.halfmoveClock
 }
 
-func (board *Board) GetClickedAtPrevious() (x, y int) {
-	return int(board.clickedAtPreviousFrame.x), int(board.clickedAtPreviousFrame.y)
+// Returns the number of the current move, starting at 1
+func (board *Board) GetFullmoveNumber() int {
+	return board.fullmoveNumber
 }
 
 // Function that returns true if there is a piece at position p
 func (board *Board) isThereAPieceAt(pos int) bool {
-	// copy the table value
-	b := board.tableCurrentFrame
-	twoToThe63 := uint64(math.Pow(2, 63))
 	// return true if there is a one at position 'pos' of the table
 	// (bitmap implementation)
-	return ((uint64(b) << pos) & twoToThe63) == twoToThe63
-}
-
-func (board *Board) UpdateState() {
-	// Update table code here ...
-	board.tablePreviousFrame = board.tableCurrentFrame
-	board.clickedPreviousFrame = board.clickedCurrentFrame
-	board.clickedAtPreviousFrame = board.clickedAtCurrentFrame
-}
-
-func (board *Board) Move(p *Piece, x, y int) {
-	to := y*globals.TableDim + x
-	from := int(p.getPosition())
-	// delete old position
-	delete(board.pieces, from)
-	// change bit in table current frame
-	board.tableCurrentFrame.move(from, to)
-	// change key to map
-	board.pieces[to] = p
-	// change new position to piece
-	p.MoveTo(to)
+	return board.tableCurrentFrame.has(pos)
 }
 
-func (board *Board) SetAvailableMovements(p *Piece) {
-	// check if there's no piece at the given position
-	board.availablePositions = p.GetAvailableMovements(board)
-}
-
-func (board *Board) GetPieceAt(xpos, ypos int) (piece *Piece, err error) {
-	// Get piece in position xpos,ypos
-	piece, exists := board.pieces[ypos*globals.TableDim+xpos]
+// Makes the move m and gives the turn to the other player. If the move is a
+// pawn promotion, m.Promotion is the piece the pawn becomes, otherwise it has to
+// be NoPromotion. The flags of m are not taken into account.
+// Returns ErrNoPieceAtPos if there is no piece to move, ErrWrongTurn if the piece
+// does not belong to the player to move, ErrInvalidPromotion if the promotion
+// piece does not match the move and ErrIllegalMove if the move is not legal
+func (board *Board) Move(m Move) error {
+	p, exists := board.pieceAt(m.From)
 	if !exists {
-		err = ErrNoPieceAtPos
+		return ErrNoPieceAtPos
 	}
-	return piece, err
-}
-
-func (board *Board) IsItAvailablePosition(xpos, ypos int) (available bool) {
-	for i := 0; i < len(board.availablePositions) && !available; i++ {
-		pos := board.availablePositions[i]
-		available = (pos == ypos*globals.TableDim+xpos)
+	if p.GetColor() != board.sideToMove {
+		return ErrWrongTurn
 	}
-	return available
-}
-
-func (board *Board) IsNilTable() bool {
-	return board.tableCurrentFrame == tNilValue
-}
-
-func (board *Board) HasChanged() bool {
-	return ((board.clickedCurrentFrame != board.clickedPreviousFrame) ||
-		(board.tableCurrentFrame != board.tablePreviousFrame) ||
-		(board.clickedAtCurrentFrame != board.clickedAtPreviousFrame))
+	promotes := board.IsPromotion(m)
+	if (promotes && !isValidPromotion(p.GetColor(), m.Promotion)) ||
+		(!promotes && m.Promotion != NoPromotion) {
+		return ErrInvalidPromotion
+	}
+	if !p.canMoveTo(board, m.To) {
+		return ErrIllegalMove
+	}
+	// a new move makes the undone moves impossible to redo
+	board.redoMoves = nil
+	board.push(m)
+	return nil
 }
 
-func (board *Board) IsClicked() bool {
-	return board.clickedCurrentFrame
+// Makes the move m, which has to be legal, and saves it in the history
+func (board *Board) push(m Move) {
+	san := board.ToSAN(m)
+	u := board.makeMove(m)
+	u.san = san
+	board.history = append(board.history, u)
 }
 
-func (board *Board) SetClicked(cl bool) {
-	board.clickedCurrentFrame = cl
+// Performs the move m, applying the side effects of special moves and updating
+// the state of the game. A pawn moving is promoted to a piece of type
+// m.Promotion, unless it is NoPromotion. Returns what is needed to unmake it
+func (board *Board) makeMove(m Move) undoInfo {
+	from, to := m.From, m.To
+	p, _ := board.pieceAt(from)
+	u := undoInfo{
+		move:           m,
+		castlingRights: board.castlingRights,
+		enPassant:      board.enPassant,
+		halfmoveClock:  board.halfmoveClock,
+		hash:           board.hash,
+		capturedAt:     to,
+	}
+	// the state of the game is taken out of the key, and put back once updated
+	board.hash ^= board.stateHash()
+	// a pawn capturing en passant does not land where the captured pawn is
+	if board.isEnPassantCapture(from, to) {
+		u.capturedAt = enPassantCapturedAt(from, to)
+	}
+	u.captured, _ = board.pieceAt(u.capturedAt)
+	// captures and pawn moves reset the halfmove clock
+	board.halfmoveClock++
+	if pt := p.GetPieceType(); pt == WhitePawn || pt == BlackPawn || u.captured != nil {
+		board.halfmoveClock = 0
+	}
+	if board.sideToMove == Black {
+		board.fullmoveNumber++
+	}
+	// moving the king or a rook (or capturing a rook) loses castling rights
+	board.castlingRights &^= castlingRightsLost[from] | castlingRightsLost[to]
+	if u.capturedAt != to {
+		board.removePiece(u.capturedAt)
+	}
+	board.movePiece(from, to)
+	if m.Promotion != NoPromotion {
+		board.removePiece(to)
+		p.promote(m.Promotion)
+		board.putPiece(to, p)
+	}
+	// a castling king brings the rook along
+	if pt := p.GetPieceType(); (pt == WhiteKing || pt == BlackKing) && (to-from == 2 || from-to == 2) {
+		board.moveCastlingRook(from, to)
+	}
+	// only a pawn that has just moved two cells can be captured en passant
+	board.enPassant = noEnPassant
+	if pt := p.GetPieceType(); (pt == WhitePawn || pt == BlackPawn) && (to-from == 16 || from-to == 16) {
+		board.enPassant = (from + to) / 2
+	}
+	board.sideToMove = board.sideToMove.Opponent()
+	board.hash ^= board.stateHash()
+	return u
 }
 
-func (board *Board) SetClickedAt(x, y int) {
-	board.clickedAtCurrentFrame.x = uint(x)
-	board.clickedAtCurrentFrame.y = uint(y)
+// Returns the piece at position pos, and false if the cell is empty
+func (board *Board) pieceAt(pos int) (*Piece, bool) {
+	if !board.tableCurrentFrame.has(pos) {
+		return nil, false
+	}
+	c := White
+	if board.colorTables[Black].has(pos) {
+		c = Black
+	}
+	// piece types of the same color are two numbers apart
+	for pt := PieceType(c); pt <= BlackQueen; pt += 2 {
+		if board.pieceTables[pt].has(pos) {
+			return NewPiece(pt, pos), true
+		}
+	}
+	return nil, false
 }
 
-func (board *Board) ResetMovements() {
-	board.availablePositions = make([]int, 0)
+// Returns every piece of the board, in the order of the cells they are at
+func (board *Board) GetPieces() (pieces []*Piece) {
+	for _, pos := range board.tableCurrentFrame.positions() {
+		p, _ := board.pieceAt(pos)
+		pieces = append(pieces, p)
+	}
+	return
 }
 
-func (board *Board) LoadImages() {
-
-	currDir, err := os.Getwd()
-	if err != nil {
-		log.Fatalln(err.Error())
+// Returns every piece of color c, in the order of the cells they are at
+func (board *Board) getPiecesOf(c Color) (pieces []*Piece) {
+	for _, pos := range board.colorTables[c].positions() {
+		p, _ := board.pieceAt(pos)
+		pieces = append(pieces, p)
 	}
-
-	images = make(map[PieceType]*ebiten.Image)
-
-	// Append textures so we don't have to search them after this
-	images[WhitePawn] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_plt60.png", currDir, "assets/images"))
-	images[BlackPawn] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_pdt60.png", currDir, "assets/images"))
-	images[WhiteBishop] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_blt60.png", currDir, "assets/images"))
-	images[BlackBishop] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_bdt60.png", currDir, "assets/images"))
-	images[WhiteKnight] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_nlt60.png", currDir, "assets/images"))
-	images[BlackKnight] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_ndt60.png", currDir, "assets/images"))
-	images[WhiteRook] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_rlt60.png", currDir, "assets/images"))
-	images[BlackRook] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_rdt60.png", currDir, "assets/images"))
-	images[WhiteKing] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_klt60.png", currDir, "assets/images"))
-	images[BlackKing] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_kdt60.png", currDir, "assets/images"))
-	images[WhiteQueen] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_qlt60.png", currDir, "assets/images"))
-	images[BlackQueen] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_qdt60.png", currDir, "assets/images"))
-
+	return
 }
 
-func (board *Board) InitBoard() {
-
-	// Initial table values
-	board.tableCurrentFrame = tInitValue
-	board.tablePreviousFrame = tNilValue
-
-	// initial clicked at values
-	board.clickedAtCurrentFrame = coordinate{0, 0}
-	board.clickedAtPreviousFrame = coordinate{0, 0}
-
-	// Set initial pieces values
-	board.pieces = make(map[int]*Piece)
-	// White and black's pawns
-	for i := 0; i < 8; i++ {
-		board.pieces[i+48] = NewPiece(WhitePawn, 48+i)
-		board.pieces[i+8] = NewPiece(BlackPawn, 8+i)
+// Removes the piece at position pos from the board
+func (board *Board) removePiece(pos int) {
+	p, exists := board.pieceAt(pos)
+	if !exists {
+		return
 	}
-	// White player's pieces
-	board.pieces[58] = NewPiece(WhiteBishop, 58)
-	board.pieces[61] = NewPiece(WhiteBishop, 61)
-	board.pieces[57] = NewPiece(WhiteKnight, 57)
-	board.pieces[62] = NewPiece(WhiteKnight, 62)
-	board.pieces[56] = NewPiece(WhiteRook, 56)
-	board.pieces[63] = NewPiece(WhiteRook, 63)
-	board.pieces[60] = NewPiece(WhiteKing, 60)
-	board.pieces[59] = NewPiece(WhiteQueen, 59)
-	// Black player's pieces
-	board.pieces[2] = NewPiece(BlackBishop, 2)
-	board.pieces[5] = NewPiece(BlackBishop, 5)
-	board.pieces[1] = NewPiece(BlackKnight, 1)
-	board.pieces[6] = NewPiece(BlackKnight, 6)
-	board.pieces[0] = NewPiece(BlackRook, 0)
-	board.pieces[7] = NewPiece(BlackRook, 7)
-	board.pieces[4] = NewPiece(BlackKing, 4)
-	board.pieces[3] = NewPiece(BlackQueen, 3)
+	board.pieceTables[p.GetPieceType()].clear(pos)
+	board.colorTables[p.GetColor()].clear(pos)
+	board.tableCurrentFrame.clear(pos)
+	board.hash ^= pieceKeys[p.GetPieceType()][pos]
+}
 
+// Puts the piece p at position pos of the board
+func (board *Board) putPiece(pos int, p *Piece) {
+	p.MoveTo(pos)
+	board.pieceTables[p.GetPieceType()].set(pos)
+	board.colorTables[p.GetColor()].set(pos)
+	board.tableCurrentFrame.set(pos)
+	board.hash ^= pieceKeys[p.GetPieceType()][pos]
 }
 
-func (board *Board) Paint(screen *ebiten.Image) {
-	board.paintCells(screen)
-	board.paintPieces(screen)
-	board.paintAvailableMovements(screen)
+// Moves the piece at from to the position to, capturing whatever piece was there
+func (board *Board) movePiece(from, to int) {
+	p, _ := board.pieceAt(from)
+	// delete old position, and the captured piece
+	board.removePiece(from)
+	board.removePiece(to)
+	// put the piece in the new position
+	board.putPiece(to, p)
 }
 
-func (board *Board) paintCells(screen *ebiten.Image) {
-	cWidth := int(globals.WindowWidth / tDimensions)
-	cHeight := int(globals.WindowHeight / tDimensions)
-	for i := 0; i < tDimensions; i++ {
-		for j := 0; j < tDimensions; j++ {
-			if (i+j)%2 == 0 {
-				ebitenutil.DrawRect(screen, float64(i*cWidth), float64(j*cHeight), float64(cWidth), float64(cHeight), color.White)
-			} else {
-				ebitenutil.DrawRect(screen, float64(i*cWidth), float64(j*cHeight), float64(cWidth), float64(cHeight), color.Black)
-			}
-		}
+// Returns a copy of the position of the board (the tables and the state of the
+// game), so moves can be tried on it without modifying the original board
+func (board *Board) clone() *Board {
+	return &Board{
+		pieceTables:       board.pieceTables,
+		colorTables:       board.colorTables,
+		tableCurrentFrame: board.tableCurrentFrame,
+		sideToMove:        board.sideToMove,
+		castlingRights:    board.castlingRights,
+		enPassant:         board.enPassant,
+		halfmoveClock:     board.halfmoveClock,
+		fullmoveNumber:    board.fullmoveNumber,
+		hash:              board.hash,
 	}
 }
 
-func (board *Board) paintPieces(screen *ebiten.Image) {
-	// TODO: refactor
-	for _, p := range board.pieces {
-		if *p != Piece(0) {
-			// get x and y coordinates
-			// pPosition := p.getPosition()
-			logX, logY := p.GetLogicalPosition()
-			x, y := utils.GetAbsolutePosition(logX, logY)
-			// Center the images
-			x += (float64(globals.CWidth) - 60) / 2
-			y += (float64(globals.CHeight) - 60) / 2
-			// Apply transformations
-			geom := &ebiten.GeoM{}
-			geom.Translate(x, y)
-			// TODO read images in SVG format and scale them
-			screen.DrawImage(
-				images[p.getPieceType()],
-				&ebiten.DrawImageOptions{
-					GeoM: *geom,
-				},
-			)
-		}
-	}
+// Returns a copy of the position of the board, which can be used without
+// modifying the board, for instance to search it in another goroutine
+func (board *Board) Copy() *Board {
+	return board.clone()
 }
 
-func (board *Board) paintAvailableMovements(screen *ebiten.Image) {
-	if !board.IsClicked() {
-		return
-	}
-	// get image
-	currDir, err := os.Getwd()
-	if err != nil {
-		log.Fatalln(err)
-	}
-	imgPath := fmt.Sprintf("%s/%s/%s", currDir, "assets/images", "movement.png")
-	movementImage := utils.NewImage(imgPath)
-	// draw red circles at the given positions
-	for _, p := range board.availablePositions {
-		// get x and y coordinates
-		xLogic := int(p % globals.TableDim)
-		yLogic := int(p / globals.TableDim)
-		// center the dots
-		x := float64(xLogic * globals.CWidth)
-		y := float64(yLogic * globals.CHeight)
-		// declare geom struct
-		geom := &ebiten.GeoM{}
-		// scale (first)
-		xf := float64(globals.CWidth) / float64(movementImage.Bounds().Dx())
-		yf := float64(globals.CHeight) / float64(movementImage.Bounds().Dy())
-		geom.Scale(xf, yf)
-		// translate (then translate)
-		geom.Translate(x, y)
-		// draw image
-		screen.DrawImage(movementImage, &ebiten.DrawImageOptions{
-			GeoM: *geom,
-		})
+func (board *Board) GetPieceAt(xpos, ypos int) (piece *Piece, err error) {
+	// Get piece in position xpos,ypos
+	piece, exists := board.pieceAt(ypos*globals.TableDim + xpos)
+	if !exists {
+		err = ErrNoPieceAtPos
 	}
+	return piece, err
 }
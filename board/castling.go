@@ -0,0 +1,116 @@
+package board
+
+// CastlingRights is a bitmap that represents which castling moves are still
+// available for each player. A right is lost once the king or the rook involved
+// moves, or when the rook is captured
+type CastlingRights uint8
+
+const (
+	WhiteKingside CastlingRights = 1 << iota
+	WhiteQueenside
+	BlackKingside
+	BlackQueenside
+
+	NoCastlingRights  CastlingRights = 0
+	AllCastlingRights                = WhiteKingside | WhiteQueenside | BlackKingside | BlackQueenside
+)
+
+// Initial positions of the kings and the rooks involved in castling
+const (
+	whiteKingStart          = 60
+	whiteKingsideRookStart  = 63
+	whiteQueensideRookStart = 56
+	blackKingStart          = 4
+	blackKingsideRookStart  = 7
+	blackQueensideRookStart = 0
+)
+
+// Rights that are lost when a piece moves from, or is captured at, each position
+var castlingRightsLost = [64]CastlingRights{
+	whiteKingStart:          WhiteKingside | WhiteQueenside,
+	whiteKingsideRookStart:  WhiteKingside,
+	whiteQueensideRookStart: WhiteQueenside,
+	blackKingStart:          BlackKingside | BlackQueenside,
+	blackKingsideRookStart:  BlackKingside,
+	blackQueensideRookStart: BlackQueenside,
+}
+
+// Pieces that have to be at each initial position for the castling rights
+// involving them to be valid
+var castlingPieces = map[int]PieceType{
+	whiteKingStart:          WhiteKing,
+	whiteKingsideRookStart:  WhiteRook,
+	whiteQueensideRookStart: WhiteRook,
+	blackKingStart:          BlackKing,
+	blackKingsideRookStart:  BlackRook,
+	blackQueensideRookStart: BlackRook,
+}
+
+// Returns true if all the rights in r are still available
+func (cr CastlingRights) Has(r CastlingRights) bool {
+	return cr&r == r
+}
+
+func (board *Board) GetCastlingRights() CastlingRights {
+	return board.castlingRights
+}
+
+// Returns the positions the king k can move to by castling. A player can castle
+// if it still has the right to, there are no pieces in between the king and the
+// rook, and the king is not in check, does not go through an attacked cell and
+// does not end up in check
+func (board *Board) getCastlingMovements(k *Piece) (newPositions []int) {
+	c := k.GetColor()
+	kingStart, kingside, queenside := whiteKingStart, WhiteKingside, WhiteQueenside
+	if c == Black {
+		kingStart, kingside, queenside = blackKingStart, BlackKingside, BlackQueenside
+	}
+	if k.getPosition() != kingStart || board.IsInCheck(c) {
+		return
+	}
+	if board.castlingRights.Has(kingside) &&
+		board.areEmpty(kingStart+1, kingStart+2) &&
+		!board.areAttacked(c.Opponent(), kingStart+1, kingStart+2) {
+		newPositions = append(newPositions, kingStart+2)
+	}
+	// in queenside castling the rook goes through one more cell, which has to be
+	// empty but can be attacked
+	if board.castlingRights.Has(queenside) &&
+		board.areEmpty(kingStart-1, kingStart-2, kingStart-3) &&
+		!board.areAttacked(c.Opponent(), kingStart-1, kingStart-2) {
+		newPositions = append(newPositions, kingStart-2)
+	}
+	return
+}
+
+// Moves the rook involved in castling once the king has been moved from the
+// position from to the position to
+func (board *Board) moveCastlingRook(from, to int) {
+	if to > from {
+		// kingside, the rook jumps from the right corner to the left of the king
+		board.movePiece(from+3, from+1)
+	} else {
+		// queenside, the rook jumps from the left corner to the right of the king
+		board.movePiece(from-4, from-1)
+	}
+}
+
+// Returns true if there is no piece at any of the given positions
+func (board *Board) areEmpty(positions ...int) bool {
+	for _, pos := range positions {
+		if board.isThereAPieceAt(pos) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if any of the given positions is attacked by the player of color by
+func (board *Board) areAttacked(by Color, positions ...int) bool {
+	for _, pos := range positions {
+		if board.isAttacked(pos, by) {
+			return true
+		}
+	}
+	return false
+}
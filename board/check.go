@@ -0,0 +1,76 @@
+package board
+
+// A Result represents the state of a game
+type Result uint8
+
+const (
+	// the game is still being played
+	Ongoing Result = iota
+	// the player to move is in check and has no legal moves
+	Checkmate
+	// the player to move is not in check but has no legal moves
+	Stalemate
+)
+
+func (r Result) String() string {
+	switch r {
+	case Checkmate:
+		return "checkmate"
+	case Stalemate:
+		return "stalemate"
+	default:
+		return "ongoing"
+	}
+}
+
+// Returns the position of the king of color c, and false if there's no such king
+func (board *Board) findKing(c Color) (int, bool) {
+	king := WhiteKing
+	if c == Black {
+		king = BlackKing
+	}
+	if board.pieceTables[king] == 0 {
+		return 0, false
+	}
+	return board.pieceTables[king].positions()[0], true
+}
+
+// Returns true if the king of color c is in check
+func (board *Board) IsInCheck(c Color) bool {
+	kpos, found := board.findKing(c)
+	if !found {
+		return false
+	}
+	return board.isAttacked(kpos, c.Opponent())
+}
+
+// Returns true if moving the piece p to the position to leaves its own king in check
+func (board *Board) leavesKingInCheck(p *Piece, to int) bool {
+	// try the move in a copy of the board
+	b := board.clone()
+	// the promotion piece does not matter, it cannot protect its own king
+	b.makeMove(Move{From: p.getPosition(), To: to})
+	return b.IsInCheck(p.GetColor())
+}
+
+// Returns true if the player of color c has at least one legal move
+func (board *Board) HasLegalMoves(c Color) bool {
+	for _, p := range board.getPiecesOf(c) {
+		if len(p.GetAvailableMovements(board)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the result of the game for the player to move
+func (board *Board) GetResult() Result {
+	c := board.sideToMove
+	if board.HasLegalMoves(c) {
+		return Ongoing
+	}
+	if board.IsInCheck(c) {
+		return Checkmate
+	}
+	return Stalemate
+}
@@ -0,0 +1,41 @@
+package board
+
+import "ChessEngine/globals"
+
+// Value of the en passant cell when no pawn can be captured en passant
+const noEnPassant = -1
+
+// Returns the cell a pawn can move to by capturing en passant, and false if
+// there is none
+func (board *Board) GetEnPassant() (pos int, ok bool) {
+	return board.enPassant, board.enPassant != noEnPassant
+}
+
+// Returns true if the pawn p can move diagonally to the empty cell at position
+// pos by capturing en passant. Only the player to move can capture en passant,
+// since the right is lost after any other move
+func (board *Board) canCaptureEnPassant(p *Piece, pos int) bool {
+	pt := p.GetPieceType()
+	return (pt == WhitePawn || pt == BlackPawn) &&
+		p.GetColor() == board.sideToMove &&
+		pos == board.enPassant
+}
+
+// Returns true if moving the piece at from to the position to is an en passant
+// capture
+func (board *Board) isEnPassantCapture(from, to int) bool {
+	p, exists := board.pieceAt(from)
+	if !exists || to != board.enPassant {
+		return false
+	}
+	// the pawn has to move diagonally
+	return board.canCaptureEnPassant(p, to) &&
+		from%globals.TableDim != to%globals.TableDim
+}
+
+// Returns the position of the pawn captured en passant by a pawn moving from the
+// position from to the position to. It is in the same row the capturing pawn
+// starts and in the same column it ends
+func enPassantCapturedAt(from, to int) int {
+	return (from/globals.TableDim)*globals.TableDim + to%globals.TableDim
+}
@@ -0,0 +1,257 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ChessEngine/globals"
+)
+
+// StartFEN is the initial position of a game in Forsyth-Edwards Notation
+const StartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+var ErrInvalidFEN = errors.New("invalid FEN")
+
+// Letters that represent each type of piece. White pieces are uppercase and
+// black pieces are lowercase
+var pieceLetters = map[PieceType]byte{
+	WhitePawn:   'P',
+	BlackPawn:   'p',
+	WhiteKnight: 'N',
+	BlackKnight: 'n',
+	WhiteBishop: 'B',
+	BlackBishop: 'b',
+	WhiteRook:   'R',
+	BlackRook:   'r',
+	WhiteKing:   'K',
+	BlackKing:   'k',
+	WhiteQueen:  'Q',
+	BlackQueen:  'q',
+}
+
+// Letters that represent each castling right
+var castlingLetters = []struct {
+	right  CastlingRights
+	letter byte
+}{
+	{WhiteKingside, 'K'},
+	{WhiteQueenside, 'Q'},
+	{BlackKingside, 'k'},
+	{BlackQueenside, 'q'},
+}
+
+// Sets up the board with the position described by fen, in Forsyth-Edwards
+// Notation. The move counters can be left out, in which case they are 0 and 1.
+// The board is not modified if the FEN is not valid
+func (board *Board) LoadFEN(fen string) error {
+	fields := strings.Fields(fen)
+	if len(fields) == 4 {
+		fields = append(fields, "0", "1")
+	}
+	if len(fields) != 6 {
+		return fmt.Errorf("%w: expected 4 or 6 fields, got %d", ErrInvalidFEN, len(fields))
+	}
+
+	// piece placement, from the 8th rank to the 1st one
+	var pieces []*Piece
+	rows := strings.Split(fields[0], "/")
+	if len(rows) != globals.TableDim {
+		return fmt.Errorf("%w: expected %d ranks, got %d", ErrInvalidFEN, globals.TableDim, len(rows))
+	}
+	for y, row := range rows {
+		x := 0
+		for _, r := range row {
+			if r >= '1' && r <= '8' {
+				x += int(r - '0')
+				continue
+			}
+			pt, ok := letterToPieceType(byte(r))
+			if !ok {
+				return fmt.Errorf("%w: unknown piece %q", ErrInvalidFEN, r)
+			}
+			if x >= globals.TableDim {
+				return fmt.Errorf("%w: rank %d is too long", ErrInvalidFEN, globals.TableDim-y)
+			}
+			pos := y*globals.TableDim + x
+			pieces = append(pieces, NewPiece(pt, pos))
+			x++
+		}
+		if x != globals.TableDim {
+			return fmt.Errorf("%w: rank %d does not have %d cells", ErrInvalidFEN, globals.TableDim-y, globals.TableDim)
+		}
+	}
+
+	// side to move
+	var side Color
+	switch fields[1] {
+	case "w":
+		side = White
+	case "b":
+		side = Black
+	default:
+		return fmt.Errorf("%w: unknown side to move %q", ErrInvalidFEN, fields[1])
+	}
+
+	// each player has exactly one king, and the player that has just moved
+	// cannot have left it in check
+	kings := [2]int{}
+	for _, p := range pieces {
+		if pt := p.GetPieceType(); pt == WhiteKing || pt == BlackKing {
+			kings[p.GetColor()]++
+		}
+	}
+	if kings[White] != 1 || kings[Black] != 1 {
+		return fmt.Errorf("%w: expected one king per player, got %d white and %d black", ErrInvalidFEN, kings[White], kings[Black])
+	}
+	position := &Board{}
+	for _, p := range pieces {
+		position.putPiece(p.getPosition(), NewPiece(p.GetPieceType(), p.getPosition()))
+	}
+	if position.IsInCheck(side.Opponent()) {
+		return fmt.Errorf("%w: the %s king is in check and it is not its turn", ErrInvalidFEN, side.Opponent())
+	}
+
+	// castling rights
+	rights := NoCastlingRights
+	if fields[2] != "-" {
+		for i := 0; i < len(fields[2]); i++ {
+			found := false
+			for _, cl := range castlingLetters {
+				if cl.letter == fields[2][i] {
+					rights |= cl.right
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("%w: unknown castling right %q", ErrInvalidFEN, fields[2][i])
+			}
+		}
+	}
+
+	// en passant cell
+	enPassant := noEnPassant
+	if fields[3] != "-" {
+		pos, err := ParseSquare(fields[3])
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidFEN, err.Error())
+		}
+		// the cell was skipped by a pawn of the opponent that has just moved two
+		// cells, so it is in the 6th rank if white moves and the 3rd rank if
+		// black moves, it is empty and the pawn is right behind it
+		row, pawn, behind := 2, BlackPawn, pos+globals.TableDim
+		if side == Black {
+			row, pawn, behind = 5, WhitePawn, pos-globals.TableDim
+		}
+		p, exists := position.pieceAt(behind)
+		if pos/globals.TableDim != row || position.isThereAPieceAt(pos) || !exists || p.GetPieceType() != pawn {
+			return fmt.Errorf("%w: invalid en passant cell %q", ErrInvalidFEN, fields[3])
+		}
+		enPassant = pos
+	}
+
+	// move counters
+	halfmoveClock, err := strconv.Atoi(fields[4])
+	if err != nil || halfmoveClock < 0 {
+		return fmt.Errorf("%w: invalid halfmove clock %q", ErrInvalidFEN, fields[4])
+	}
+	fullmoveNumber, err := strconv.Atoi(fields[5])
+	if err != nil || fullmoveNumber < 1 {
+		return fmt.Errorf("%w: invalid fullmove number %q", ErrInvalidFEN, fields[5])
+	}
+
+	board.pieceTables = [12]table{}
+	board.colorTables = [2]table{}
+	board.tableCurrentFrame = 0
+	board.hash = 0
+	for _, p := range pieces {
+		board.putPiece(p.getPosition(), p)
+	}
+	board.sideToMove = side
+	board.castlingRights = rights
+	board.enPassant = enPassant
+	board.halfmoveClock = halfmoveClock
+	board.fullmoveNumber = fullmoveNumber
+	// a new game starts from this position
+	board.startFEN = strings.Join(fields, " ")
+	board.history = nil
+	board.redoMoves = nil
+	board.searchHistory = nil
+	// drop the castling rights of kings and rooks that are not in their initial
+	// positions, they cannot have the right to castle
+	for pos, pt := range castlingPieces {
+		if p, exists := board.pieceAt(pos); !exists || p.GetPieceType() != pt {
+			board.castlingRights &^= castlingRightsLost[pos]
+		}
+	}
+	// the pieces are already in the key
+	board.hash ^= board.stateHash()
+	return nil
+}
+
+// Returns the position of the board in Forsyth-Edwards Notation
+func (board *Board) GetFEN() string {
+	var sb strings.Builder
+
+	// piece placement, from the 8th rank to the 1st one
+	for y := 0; y < globals.TableDim; y++ {
+		empty := 0
+		for x := 0; x < globals.TableDim; x++ {
+			p, exists := board.pieceAt(y*globals.TableDim + x)
+			if !exists {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			sb.WriteByte(pieceLetters[p.GetPieceType()])
+		}
+		if empty > 0 {
+			sb.WriteString(strconv.Itoa(empty))
+		}
+		if y < globals.TableDim-1 {
+			sb.WriteByte('/')
+		}
+	}
+
+	// side to move
+	if board.sideToMove == White {
+		sb.WriteString(" w ")
+	} else {
+		sb.WriteString(" b ")
+	}
+
+	// castling rights
+	if board.castlingRights == NoCastlingRights {
+		sb.WriteByte('-')
+	}
+	for _, cl := range castlingLetters {
+		if board.castlingRights.Has(cl.right) {
+			sb.WriteByte(cl.letter)
+		}
+	}
+
+	// en passant cell
+	if pos, ok := board.GetEnPassant(); ok {
+		sb.WriteString(" " + SquareName(pos))
+	} else {
+		sb.WriteString(" -")
+	}
+
+	// move counters
+	sb.WriteString(fmt.Sprintf(" %d %d", board.halfmoveClock, board.fullmoveNumber))
+	return sb.String()
+}
+
+// Returns the type of piece represented by the letter l
+func letterToPieceType(l byte) (PieceType, bool) {
+	for pt, pl := range pieceLetters {
+		if pl == l {
+			return pt, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,62 @@
+package board
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoadFEN(t *testing.T) {
+	// positions written back as they were read
+	for _, tt := range perftTests {
+		b := &Board{}
+		if err := b.LoadFEN(tt.fen); err != nil {
+			t.Fatalf("%s: %v", tt.fen, err)
+		}
+		if fen := b.GetFEN(); fen != tt.fen {
+			t.Errorf("got %s, want %s", fen, tt.fen)
+		}
+	}
+	// the move counters can be left out
+	b := &Board{}
+	if err := b.LoadFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"; b.GetFEN() != want {
+		t.Errorf("got %s, want %s", b.GetFEN(), want)
+	}
+}
+
+func TestLoadFENInvalid(t *testing.T) {
+	tests := []struct{ name, fen string }{
+		{"missing fields", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq"},
+		{"too many fields", StartFEN + " 1"},
+		{"missing rank", "rnbqkbnr/pppppppp/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"},
+		{"long rank", "rnbqkbnr/ppppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"},
+		{"unknown piece", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNX w KQkq - 0 1"},
+		{"unknown side", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1"},
+		{"unknown castling", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkx - 0 1"},
+		{"en passant cell", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e4 0 1"},
+		{"en passant cell of the side to move", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e3 0 1"},
+		{"en passant cell without a pawn", "4k3/8/8/8/8/8/3PP3/4K3 w - e3 0 1"},
+		{"en passant cell without a pawn behind", "rnbqkbnr/pppp1ppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e6 0 1"},
+		{"occupied en passant cell", "rnbqkbnr/pppp1ppp/4n3/4p3/8/8/PPPPPPPP/R1BQKBNR w KQkq e6 0 1"},
+		{"halfmove clock", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - -1 1"},
+		{"fullmove number", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0"},
+		{"no kings", "8/8/8/8/8/8/8/8 w - - 0 1"},
+		{"two white kings", "4k3/8/8/8/8/8/8/3KK3 w - - 0 1"},
+		{"player not to move in check", "4k3/8/8/8/8/8/8/4R1K1 w - - 0 1"},
+	}
+	for _, tt := range tests {
+		b := &Board{}
+		if err := b.LoadFEN(StartFEN); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.LoadFEN(tt.fen); !errors.Is(err, ErrInvalidFEN) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, ErrInvalidFEN)
+		}
+		// the board is not modified
+		if b.GetFEN() != StartFEN {
+			t.Errorf("%s: board changed to %s", tt.name, b.GetFEN())
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package board
+
+import "errors"
+
+var (
+	ErrNoMoveToUndo = errors.New("there is no move to undo")
+	ErrNoMoveToRedo = errors.New("there is no move to redo")
+)
+
+// An undoInfo saves the state of the board that is lost when a move is made,
+// so the move can be unmade and the board left exactly as it was before
+type undoInfo struct {
+	// the move itself
+	move Move
+	// the move in Standard Algebraic Notation, for the game record
+	san string
+	// piece captured by the move, nil if there was none, and the position it
+	// was at. It is not the destination of the move when capturing en passant
+	captured   *Piece
+	capturedAt int
+	// state of the game before the move
+	castlingRights CastlingRights
+	enPassant      int
+	halfmoveClock  int
+	hash           uint64
+}
+
+// Unmakes the move saved in u, which has to be the last move made
+func (board *Board) unmakeMove(u undoInfo) {
+	from, to := u.move.From, u.move.To
+	p, _ := board.pieceAt(to)
+	board.sideToMove = board.sideToMove.Opponent()
+	if board.sideToMove == Black {
+		board.fullmoveNumber--
+	}
+	// the rook goes back to its corner
+	if pt := p.GetPieceType(); (pt == WhiteKing || pt == BlackKing) && (to-from == 2 || from-to == 2) {
+		if to > from {
+			board.movePiece(from+1, from+3)
+		} else {
+			board.movePiece(from-1, from-4)
+		}
+	}
+	board.movePiece(to, from)
+	// a promoted piece goes back to be a pawn
+	if u.move.Promotion != NoPromotion {
+		board.removePiece(from)
+		if p.GetColor() == White {
+			p.promote(WhitePawn)
+		} else {
+			p.promote(BlackPawn)
+		}
+		board.putPiece(from, p)
+	}
+	if u.captured != nil {
+		board.putPiece(u.capturedAt, u.captured)
+	}
+	board.castlingRights = u.castlingRights
+	board.enPassant = u.enPassant
+	board.halfmoveClock = u.halfmoveClock
+	board.hash = u.hash
+}
+
+// Unmakes the last move made, which can be made again with Redo. Returns
+// ErrNoMoveToUndo if no move has been made since the position was set, or a
+// move made with MakeMove has not been unmade yet
+func (board *Board) Undo() error {
+	if len(board.history) == 0 || len(board.searchHistory) > 0 {
+		return ErrNoMoveToUndo
+	}
+	u := board.history[len(board.history)-1]
+	board.history = board.history[:len(board.history)-1]
+	board.unmakeMove(u)
+	board.redoMoves = append(board.redoMoves, u.move)
+	return nil
+}
+
+// Makes again the last move undone. Returns ErrNoMoveToRedo if there is no
+// move undone, a new move has been made after undoing it, or a move made with
+// MakeMove has not been unmade yet
+func (board *Board) Redo() error {
+	if len(board.redoMoves) == 0 || len(board.searchHistory) > 0 {
+		return ErrNoMoveToRedo
+	}
+	m := board.redoMoves[len(board.redoMoves)-1]
+	board.redoMoves = board.redoMoves[:len(board.redoMoves)-1]
+	board.push(m)
+	return nil
+}
+
+// Returns the moves made since the position was set, in the order they were made
+func (board *Board) GetMoveHistory() []Move {
+	moves := make([]Move, len(board.history))
+	for i, u := range board.history {
+		moves[i] = u.move
+	}
+	return moves
+}
+
+// Makes the move m, which has to be one of the legal moves of the board,
+// without checking it is legal. Meant for searches, which unmake it with
+// UnmakeMove: the move is kept apart from the moves of the game, so it is not
+// written in the game record and cannot be undone or redone
+func (board *Board) MakeMove(m Move) {
+	board.searchHistory = append(board.searchHistory, board.makeMove(m))
+}
+
+// Unmakes the last move made with MakeMove. Returns ErrNoMoveToUndo if every
+// move made with MakeMove has already been unmade
+func (board *Board) UnmakeMove() error {
+	if len(board.searchHistory) == 0 {
+		return ErrNoMoveToUndo
+	}
+	board.unmakeMove(board.searchHistory[len(board.searchHistory)-1])
+	board.searchHistory = board.searchHistory[:len(board.searchHistory)-1]
+	return nil
+}
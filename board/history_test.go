@@ -0,0 +1,94 @@
+package board
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUndoRedo(t *testing.T) {
+	b := &Board{}
+	if err := b.LoadFEN(StartFEN); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{"e2e4", "e7e5", "g1f3"} {
+		if err := b.MoveUCI(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	afterMoves := b.GetFEN()
+	for i := 0; i < 3; i++ {
+		if err := b.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Undo(); !errors.Is(err, ErrNoMoveToUndo) {
+		t.Errorf("got error %v, want %v", err, ErrNoMoveToUndo)
+	}
+	if b.GetFEN() != StartFEN {
+		t.Errorf("got %s after undoing every move, want %s", b.GetFEN(), StartFEN)
+	}
+	for i := 0; i < 3; i++ {
+		if err := b.Redo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Redo(); !errors.Is(err, ErrNoMoveToRedo) {
+		t.Errorf("got error %v, want %v", err, ErrNoMoveToRedo)
+	}
+	if b.GetFEN() != afterMoves {
+		t.Errorf("got %s after redoing every move, want %s", b.GetFEN(), afterMoves)
+	}
+	// a new move makes the undone moves impossible to redo
+	b.Undo()
+	if err := b.MoveUCI("b1c3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Redo(); !errors.Is(err, ErrNoMoveToRedo) {
+		t.Errorf("got error %v, want %v", err, ErrNoMoveToRedo)
+	}
+}
+
+func TestMakeMove(t *testing.T) {
+	b := &Board{}
+	if err := b.LoadFEN(StartFEN); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.MoveUCI("e2e4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	m, err := b.ParseUCI("d2d4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the moves of a search are not part of the game, so the undone move can
+	// only be redone once they are unmade
+	b.MakeMove(m)
+	if err := b.Redo(); !errors.Is(err, ErrNoMoveToRedo) {
+		t.Errorf("got error %v redoing before unmaking, want %v", err, ErrNoMoveToRedo)
+	}
+	if err := b.Undo(); !errors.Is(err, ErrNoMoveToUndo) {
+		t.Errorf("got error %v undoing before unmaking, want %v", err, ErrNoMoveToUndo)
+	}
+	if moves := b.GetGameRecord().Moves; len(moves) != 0 {
+		t.Errorf("got game record %q, want no moves", moves)
+	}
+	if err := b.UnmakeMove(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.UnmakeMove(); !errors.Is(err, ErrNoMoveToUndo) {
+		t.Errorf("got error %v, want %v", err, ErrNoMoveToUndo)
+	}
+	if err := b.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"e4"}; !reflect.DeepEqual(b.GetGameRecord().Moves, want) {
+		t.Errorf("got game record %q, want %q", b.GetGameRecord().Moves, want)
+	}
+	if want := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"; b.GetFEN() != want {
+		t.Errorf("got %s, want %s", b.GetFEN(), want)
+	}
+}
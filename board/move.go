@@ -0,0 +1,146 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidUCI = errors.New("invalid UCI move")
+
+// MoveFlags is a bitmap that describes what kind of move a Move is
+type MoveFlags uint8
+
+const (
+	// the move captures a piece
+	FlagCapture MoveFlags = 1 << iota
+	// the move is a pawn capturing en passant
+	FlagEnPassant
+	// the move is a king castling, the rook is moved along
+	FlagCastling
+	// the move is a pawn moving two cells from its initial position
+	FlagDoublePawnPush
+	// the move is a pawn reaching the last row
+	FlagPromotion
+)
+
+// A Move is the movement of the piece at the cell From to the cell To. Special
+// moves like castling or en passant are represented by the movement of the
+// piece that moves (the king or the capturing pawn)
+type Move struct {
+	From, To int
+	// piece a pawn is promoted to, NoPromotion if the move is not a promotion
+	Promotion PieceType
+	// kind of move, as found by the board when generating it
+	Flags MoveFlags
+}
+
+// NoMove is the move of a search that found none, which is the zero Move. It
+// is written "0000" in UCI notation, as the null move of the protocol
+var NoMove = Move{}
+
+// Returns true if the move has all the flags f
+func (m Move) Is(f MoveFlags) bool {
+	return m.Flags&f == f
+}
+
+// Returns the move in the long algebraic notation used by the UCI protocol,
+// that is, the cell the piece comes from, the cell it goes to and the lowercase
+// letter of the promotion piece, like "e2e4" or "e7e8q"
+func (m Move) String() string {
+	// a piece cannot stay where it is, so the move is a null move
+	if m.From == m.To {
+		return "0000"
+	}
+	s := SquareName(m.From) + SquareName(m.To)
+	if m.Promotion != NoPromotion {
+		s += string(pieceLetters[m.Promotion|1])
+	}
+	return s
+}
+
+// Returns a move with its flags set, as a move of the piece at from to the
+// position to in this board
+func (board *Board) newMove(from, to int, promotion PieceType) Move {
+	m := Move{From: from, To: to, Promotion: promotion}
+	p, _ := board.pieceAt(from)
+	pt := p.GetPieceType()
+	if board.isThereAPieceAt(to) {
+		m.Flags |= FlagCapture
+	}
+	if board.isEnPassantCapture(from, to) {
+		m.Flags |= FlagCapture | FlagEnPassant
+	}
+	if (pt == WhiteKing || pt == BlackKing) && (to-from == 2 || from-to == 2) {
+		m.Flags |= FlagCastling
+	}
+	if (pt == WhitePawn || pt == BlackPawn) && (to-from == 16 || from-to == 16) {
+		m.Flags |= FlagDoublePawnPush
+	}
+	if promotion != NoPromotion {
+		m.Flags |= FlagPromotion
+	}
+	return m
+}
+
+// Returns every legal move of the player to move, in the order of the cells the
+// pieces are at. A pawn reaching the last row gives one move per promotion piece
+func (board *Board) GetLegalMoves() (moves []Move) {
+	for _, p := range board.getPiecesOf(board.sideToMove) {
+		pos := p.getPosition()
+		for _, npos := range p.GetAvailableMovements(board) {
+			if !board.IsPromotion(Move{From: pos, To: npos}) {
+				moves = append(moves, board.newMove(pos, npos, NoPromotion))
+				continue
+			}
+			for _, pt := range GetPromotionPieces(p.GetColor()) {
+				moves = append(moves, board.newMove(pos, npos, pt))
+			}
+		}
+	}
+	return
+}
+
+// Returns the legal move written in UCI long algebraic notation, like "e2e4" or
+// "e7e8q", for the player to move. Returns ErrInvalidUCI if the notation is not
+// valid and ErrIllegalMove if it is not a legal move
+func (board *Board) ParseUCI(s string) (Move, error) {
+	if len(s) != 4 && len(s) != 5 {
+		return NoMove, fmt.Errorf("%w: %q", ErrInvalidUCI, s)
+	}
+	from, err := ParseSquare(s[0:2])
+	if err != nil {
+		return NoMove, fmt.Errorf("%w: %q", ErrInvalidUCI, s)
+	}
+	to, err := ParseSquare(s[2:4])
+	if err != nil {
+		return NoMove, fmt.Errorf("%w: %q", ErrInvalidUCI, s)
+	}
+	// the promotion piece is written in lowercase whatever its color is
+	promotion := NoPromotion
+	if len(s) == 5 {
+		if strings.IndexByte("nbrq", s[4]) < 0 {
+			return NoMove, fmt.Errorf("%w: %q", ErrInvalidUCI, s)
+		}
+		promotion, _ = letterToPieceType(s[4])
+		if board.sideToMove == White {
+			promotion &^= 1
+		}
+	}
+	for _, m := range board.GetLegalMoves() {
+		if m.From == from && m.To == to && m.Promotion == promotion {
+			return m, nil
+		}
+	}
+	return NoMove, fmt.Errorf("%w: %q", ErrIllegalMove, s)
+}
+
+// Makes the move written in UCI long algebraic notation for the player to move.
+// Returns an error if it is not a legal move
+func (board *Board) MoveUCI(s string) error {
+	m, err := board.ParseUCI(s)
+	if err != nil {
+		return err
+	}
+	return board.Move(m)
+}
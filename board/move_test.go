@@ -0,0 +1,128 @@
+package board
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseUCI(t *testing.T) {
+	tests := []struct {
+		name, fen, uci string
+		want           Move
+	}{
+		{"pawn double push", StartFEN, "e2e4", Move{52, 36, NoPromotion, FlagDoublePawnPush}},
+		{"knight move", StartFEN, "g1f3", Move{62, 45, NoPromotion, 0}},
+		{"capture", "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2", "e4d5", Move{36, 27, NoPromotion, FlagCapture}},
+		{"en passant", "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", "e5f6", Move{28, 21, NoPromotion, FlagCapture | FlagEnPassant}},
+		{"castling", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1c1", Move{60, 58, NoPromotion, FlagCastling}},
+		{"white promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8q", Move{12, 4, WhiteQueen, FlagPromotion}},
+		{"black promotion", "4k3/8/8/8/8/8/K3p3/8 b - - 0 1", "e2e1n", Move{52, 60, BlackKnight, FlagPromotion}},
+		{"promotion capturing", "3r2k1/4P3/8/8/8/8/8/4K3 w - - 0 1", "e7d8r", Move{12, 3, WhiteRook, FlagCapture | FlagPromotion}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Board{}
+			if err := b.LoadFEN(tt.fen); err != nil {
+				t.Fatal(err)
+			}
+			m, err := b.ParseUCI(tt.uci)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m != tt.want {
+				t.Errorf("got %+v, want %+v", m, tt.want)
+			}
+			if m.String() != tt.uci {
+				t.Errorf("got %s written back, want %s", m, tt.uci)
+			}
+		})
+	}
+}
+
+func TestParseUCIInvalid(t *testing.T) {
+	tests := []struct {
+		name, fen, uci string
+		err            error
+	}{
+		{"empty", StartFEN, "", ErrInvalidUCI},
+		{"too short", StartFEN, "e2e", ErrInvalidUCI},
+		{"too long", StartFEN, "e7e8qq", ErrInvalidUCI},
+		{"unknown cell", StartFEN, "e9e4", ErrInvalidUCI},
+		{"SAN", StartFEN, "Nf3", ErrInvalidUCI},
+		{"uppercase promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8Q", ErrInvalidUCI},
+		{"promotion to king", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8k", ErrInvalidUCI},
+		{"missing promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8", ErrIllegalMove},
+		{"promotion of a move that is not", StartFEN, "e2e4q", ErrIllegalMove},
+		{"illegal move", StartFEN, "e2e5", ErrIllegalMove},
+		{"piece of the opponent", StartFEN, "e7e5", ErrIllegalMove},
+		{"empty cell", StartFEN, "e4e5", ErrIllegalMove},
+		{"null move", StartFEN, "0000", ErrInvalidUCI},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Board{}
+			if err := b.LoadFEN(tt.fen); err != nil {
+				t.Fatal(err)
+			}
+			m, err := b.ParseUCI(tt.uci)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+			if m != NoMove {
+				t.Errorf("got move %v, want NoMove", m)
+			}
+		})
+	}
+}
+
+func TestMoveUCI(t *testing.T) {
+	b := &Board{}
+	if err := b.LoadFEN(StartFEN); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{"e2e4", "e7e5", "g1f3"} {
+		if err := b.MoveUCI(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.MoveUCI("e1g1"); !errors.Is(err, ErrIllegalMove) {
+		t.Errorf("got error %v, want %v", err, ErrIllegalMove)
+	}
+	if want := "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"; b.GetFEN() != want {
+		t.Errorf("got %s, want %s", b.GetFEN(), want)
+	}
+}
+
+func TestNoMove(t *testing.T) {
+	if s := NoMove.String(); s != "0000" {
+		t.Errorf("got %s, want 0000", s)
+	}
+}
+
+func TestMoveWithoutPromotion(t *testing.T) {
+	// a move written without a promotion piece is not a promotion
+	m := Move{From: 52, To: 36}
+	if m.Promotion != NoPromotion {
+		t.Errorf("got promotion %d, want NoPromotion", m.Promotion)
+	}
+	if s := m.String(); s != "e2e4" {
+		t.Errorf("got %s, want e2e4", s)
+	}
+	b := &Board{}
+	if err := b.LoadFEN(StartFEN); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Move(m); err != nil {
+		t.Fatal(err)
+	}
+	if want := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"; b.GetFEN() != want {
+		t.Errorf("got %s, want %s", b.GetFEN(), want)
+	}
+	// a pawn reaching the last row needs the piece it is promoted to
+	if err := b.LoadFEN("8/4P3/8/8/8/8/k7/4K3 w - - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Move(Move{From: 12, To: 4}); !errors.Is(err, ErrInvalidPromotion) {
+		t.Errorf("got error %v, want %v", err, ErrInvalidPromotion)
+	}
+}
@@ -1,9 +1,12 @@
 package board
 
-// A move is a function that takes two parameters:
-// 	- The position of the piece, a number in between 0 and 63 being 0 the top left corner
-// 		and 63 the bottom right corner
-// 	- The number of times the movement has to be done
+import "ChessEngine/globals"
+
+// A move is a function that takes one parameter, the number of times the movement
+// has to be done, and returns the displacement of the piece expressed as a step:
+// the amount of columns (x) and rows (y) the piece travels. Being the cell {0,0}
+// the top left corner of the table, negative values go up/left and positive values
+// go down/right.
 //
 // This provides a general  and easy way to move pieces. For example, if we want to define
 // a rook move, we would do it like this:
@@ -14,137 +17,216 @@ package board
 // combining them using a sum.
 // For instance, a knight moves one diagonally one cell, and one cell to any other non diagonal
 // cells, which would look like this:
-// 	knight.move(UP(2)+RIGHT(1))
-type move func(int) int
+// 	knight.move(UP(2).add(RIGHT(1)))
+//
+// Working with columns and rows instead of raw cell indexes lets us know when a movement
+// leaves the board. Otherwise a piece moving right from the H column would appear in the
+// A column of the next row.
+type move func(int) step
+
+// A step is a displacement in the table, in columns (x) and rows (y)
+type step struct {
+	x, y int
+}
+
+func (s step) add(other step) step {
+	return step{s.x + other.x, s.y + other.y}
+}
 
 var (
 	// the four basic movement functions
-	UP move = func(n int) int {
-		return (-8) * n
+	UP move = func(n int) step {
+		return step{0, -n}
 	}
-	DOWN move = func(n int) int {
-		return (+8) * n
+	DOWN move = func(n int) step {
+		return step{0, +n}
 	}
-	LEFT move = func(n int) int {
-		return (-1) * n
+	LEFT move = func(n int) step {
+		return step{-n, 0}
 	}
-	RIGHT move = func(n int) int {
-		return (+1) * n
+	RIGHT move = func(n int) step {
+		return step{+n, 0}
 	}
 )
 
+// A captureRule tells what a movement can do when it reaches an occupied cell
+type captureRule uint8
+
+const (
+	// the movement attacks the cell it reaches, so it can capture a piece there
+	mayCapture captureRule = iota
+	// the movement can only end in an empty cell (pawns moving forward)
+	cannotCapture
+)
+
 type Movement struct {
 	// definition of the movement
 	Move move
 	// max amount of cells that that movement can be performed
 	Limit int
+	// what the movement can do with the pieces it finds
+	Capture captureRule
+}
+
+// Returns the position reached after performing the movement n times from the
+// position pos. ok is false if the movement leaves the board
+func (m Movement) apply(pos, n int) (npos int, ok bool) {
+	s := m.Move(n)
+	x := pos%globals.TableDim + s.x
+	y := pos/globals.TableDim + s.y
+	if x < 0 || x >= globals.TableDim || y < 0 || y >= globals.TableDim {
+		return 0, false
+	}
+	return y*globals.TableDim + x, true
 }
 
 var WhitePawnMovements = []Movement{
 	{
-		UP, 2,
+		UP, 2, cannotCapture,
 	},
 	{
-		func(i int) int {
-			return UP(i) + RIGHT(i)
-		}, 1,
+		func(i int) step {
+			return UP(i).add(RIGHT(i))
+		}, 1, mayCapture,
 	},
 	{
-		func(i int) int {
-			return UP(i) + LEFT(i)
-		}, 1,
+		func(i int) step {
+			return UP(i).add(LEFT(i))
+		}, 1, mayCapture,
 	},
 }
 
 var BlackPawnMovements = []Movement{
 	{
-		DOWN, 2,
+		DOWN, 2, cannotCapture,
 	},
 	{
-		func(i int) int {
-			return DOWN(i) + RIGHT(i)
-		}, 1,
+		func(i int) step {
+			return DOWN(i).add(RIGHT(i))
+		}, 1, mayCapture,
 	},
 	{
-		func(i int) int {
-			return DOWN(i) + LEFT(i)
-		}, 1,
+		func(i int) step {
+			return DOWN(i).add(LEFT(i))
+		}, 1, mayCapture,
 	},
 }
 
 var KnightMovements = []Movement{
 	{
-		func(i int) int {
-			return UP(2*i) + RIGHT(i)
-		}, 1,
+		func(i int) step {
+			return UP(2 * i).add(RIGHT(i))
+		}, 1, mayCapture,
 	},
 	{
-		func(i int) int {
-			return UP(2*i) + LEFT(i)
-		}, 1,
+		func(i int) step {
+			return UP(2 * i).add(LEFT(i))
+		}, 1, mayCapture,
 	},
 	{
-		func(i int) int {
-			return DOWN(2*i) + RIGHT(i)
-		}, 1,
+		func(i int) step {
+			return DOWN(2 * i).add(RIGHT(i))
+		}, 1, mayCapture,
 	},
 	{
-		func(i int) int {
-			return DOWN(2*i) + LEFT(i)
-		}, 1,
+		func(i int) step {
+			return DOWN(2 * i).add(LEFT(i))
+		}, 1, mayCapture,
 	},
 	{
-		func(i int) int {
-			return UP(i) + RIGHT(i*2)
-		}, 1,
+		func(i int) step {
+			return UP(i).add(RIGHT(i * 2))
+		}, 1, mayCapture,
 	},
 	{
-		func(i int) int {
-			return UP(i) + LEFT(i*2)
-		}, 1,
+		func(i int) step {
+			return UP(i).add(LEFT(i * 2))
+		}, 1, mayCapture,
 	},
 	{
-		func(i int) int {
-			return DOWN(i) + RIGHT(i*2)
-		}, 1,
+		func(i int) step {
+			return DOWN(i).add(RIGHT(i * 2))
+		}, 1, mayCapture,
 	},
 	{
-		func(i int) int {
-			return DOWN(i) + LEFT(i*2)
-		}, 1,
+		func(i int) step {
+			return DOWN(i).add(LEFT(i * 2))
+		}, 1, mayCapture,
 	},
 }
 
 var BishopMovements = []Movement{
 	{
-		func(i int) int {
-			return UP(i) + RIGHT(i)
-		}, 8,
+		func(i int) step {
+			return UP(i).add(RIGHT(i))
+		}, 7, mayCapture,
 	},
 	{
-		func(i int) int {
-			return UP(i) + LEFT(i)
-		}, 8,
+		func(i int) step {
+			return UP(i).add(LEFT(i))
+		}, 7, mayCapture,
 	},
 	{
-		func(i int) int {
-			return DOWN(i) + RIGHT(i)
-		}, 8,
+		func(i int) step {
+			return DOWN(i).add(RIGHT(i))
+		}, 7, mayCapture,
 	},
 	{
-		func(i int) int {
-			return DOWN(i) + LEFT(i)
-		}, 8,
+		func(i int) step {
+			return DOWN(i).add(LEFT(i))
+		}, 7, mayCapture,
 	},
 }
 
-var (
-	Movements = map[PieceType][]Movement{
-		WhitePawn:   WhitePawnMovements,
-		BlackPawn:   BlackPawnMovements,
-		WhiteKnight: KnightMovements,
-		BlackKnight: KnightMovements,
-		WhiteBishop: BishopMovements,
-		BlackBishop: BishopMovements,
-	}
-)
+var RookMovements = []Movement{
+	{
+		UP, 7, mayCapture,
+	},
+	{
+		DOWN, 7, mayCapture,
+	},
+	{
+		LEFT, 7, mayCapture,
+	},
+	{
+		RIGHT, 7, mayCapture,
+	},
+}
+
+// a queen moves like a rook and a bishop combined
+var QueenMovements = append(append([]Movement{}, RookMovements...), BishopMovements...)
+
+var KingMovements = []Movement{
+	{
+		UP, 1, mayCapture,
+	},
+	{
+		DOWN, 1, mayCapture,
+	},
+	{
+		LEFT, 1, mayCapture,
+	},
+	{
+		RIGHT, 1, mayCapture,
+	},
+	{
+		func(i int) step {
+			return UP(i).add(RIGHT(i))
+		}, 1, mayCapture,
+	},
+	{
+		func(i int) step {
+			return UP(i).add(LEFT(i))
+		}, 1, mayCapture,
+	},
+	{
+		func(i int) step {
+			return DOWN(i).add(RIGHT(i))
+		}, 1, mayCapture,
+	},
+	{
+		func(i int) step {
+			return DOWN(i).add(LEFT(i))
+		}, 1, mayCapture,
+	},
+}
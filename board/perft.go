@@ -0,0 +1,40 @@
+package board
+
+// A PerftDivision is the number of leaf nodes found below one of the moves of
+// the root position
+type PerftDivision struct {
+	Move  Move
+	Nodes int
+}
+
+// Returns the number of leaf nodes of the tree of legal moves that starts in
+// the position of the board and goes depth moves deep. The board is left as it
+// was
+func (board *Board) Perft(depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	moves := board.GetLegalMoves()
+	// the leaves are the legal moves themselves, no need to make them
+	if depth == 1 {
+		return len(moves)
+	}
+	nodes := 0
+	for _, m := range moves {
+		u := board.makeMove(m)
+		nodes += board.Perft(depth - 1)
+		board.unmakeMove(u)
+	}
+	return nodes
+}
+
+// Returns the number of leaf nodes below each legal move of the position of the
+// board, for a tree depth moves deep
+func (board *Board) PerftDivide(depth int) (divisions []PerftDivision) {
+	for _, m := range board.GetLegalMoves() {
+		u := board.makeMove(m)
+		divisions = append(divisions, PerftDivision{m, board.Perft(depth - 1)})
+		board.unmakeMove(u)
+	}
+	return
+}
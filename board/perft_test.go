@@ -0,0 +1,86 @@
+package board
+
+import "testing"
+
+// Positions with known perft results, from
+// https://www.chessprogramming.org/Perft_Results
+var perftTests = []struct {
+	name  string
+	fen   string
+	nodes []int // nodes at depth 1, 2, 3...
+}{
+	{
+		"initial position",
+		StartFEN,
+		[]int{20, 400, 8902, 197281},
+	},
+	{
+		"kiwipete",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		[]int{48, 2039, 97862},
+	},
+	{
+		"en passant and pins",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		[]int{14, 191, 2812, 43238},
+	},
+	{
+		"castling and promotions",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		[]int{6, 264, 9467},
+	},
+	{
+		"castling and promotions (mirrored)",
+		"r2q1rk1/pP1p2pp/Q4n2/bbp1p3/Np6/1B3NBn/pPPP1PPP/R3K2R b KQ - 0 1",
+		[]int{6, 264, 9467},
+	},
+	{
+		"promotion with discovered check",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		[]int{44, 1486, 62379},
+	},
+	{
+		"middlegame",
+		"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		[]int{46, 2079, 89890},
+	},
+}
+
+// Counts the leaf nodes of the tree of legal moves depth moves deep
+func countNodes(b *Board, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	nodes := 0
+	for _, m := range b.GetLegalMoves() {
+		u := b.makeMove(m)
+		nodes += countNodes(b, depth-1)
+		b.unmakeMove(u)
+	}
+	return nodes
+}
+
+func TestPerft(t *testing.T) {
+	for _, tt := range perftTests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Board{}
+			if err := b.LoadFEN(tt.fen); err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range tt.nodes {
+				depth := i + 1
+				// the deepest counts take a while
+				if testing.Short() && want > 10000 {
+					break
+				}
+				if got := countNodes(b, depth); got != want {
+					t.Errorf("depth %d: got %d nodes, want %d", depth, got, want)
+				}
+				// making and unmaking every move has to leave the board as it was
+				if fen := b.GetFEN(); fen != tt.fen {
+					t.Fatalf("depth %d: board changed to %s", depth, fen)
+				}
+			}
+		})
+	}
+}
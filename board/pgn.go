@@ -0,0 +1,309 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Maximum length of the lines of the movetext of a PGN file
+const pgnLineLength = 80
+
+var ErrInvalidPGN = errors.New("invalid PGN")
+
+// Move number written before a move, like "12." or "12..."
+var moveNumberRegexp = regexp.MustCompile(`^[0-9]+\.+`)
+
+// A GameRecord stores every move made in a game, so it can be exported in
+// Portable Game Notation
+type GameRecord struct {
+	// position the game started from, in FEN
+	StartFEN string
+	// moves of the game in Standard Algebraic Notation
+	Moves []string
+}
+
+// PGNTags are the tag pairs written at the top of a PGN file. The Result tag is
+// taken from the state of the board
+type PGNTags struct {
+	Event string
+	Site  string
+	// date the game was played at, in the format YYYY.MM.DD
+	Date  string
+	Round string
+	White string
+	Black string
+}
+
+// Returns the tags of a game played today, with unknown values for the rest of
+// the tags
+func NewPGNTags() PGNTags {
+	return PGNTags{
+		Event: "?",
+		Site:  "?",
+		Date:  time.Now().Format("2006.01.02"),
+		Round: "?",
+		White: "?",
+		Black: "?",
+	}
+}
+
+// Returns the record of the moves made in the board since its position was set
+func (board *Board) GetGameRecord() GameRecord {
+	record := GameRecord{StartFEN: board.startFEN}
+	for _, u := range board.history {
+		record.Moves = append(record.Moves, u.san)
+	}
+	return record
+}
+
+// Returns the result of the game as written in PGN: "1-0" if white wins, "0-1"
+// if black wins, "1/2-1/2" for a draw and "*" if the game has not ended
+func (board *Board) GetPGNResult() string {
+	switch board.GetResult() {
+	case Checkmate:
+		if board.sideToMove == White {
+			return "0-1"
+		}
+		return "1-0"
+	case Stalemate:
+		return "1/2-1/2"
+	default:
+		return "*"
+	}
+}
+
+// Writes the game played in the board to w in Portable Game Notation
+func (board *Board) WritePGN(w io.Writer, tags PGNTags) error {
+	result := board.GetPGNResult()
+	record := board.GetGameRecord()
+
+	var sb strings.Builder
+	// seven tag roster, in its standard order
+	writeTag(&sb, "Event", tags.Event)
+	writeTag(&sb, "Site", tags.Site)
+	writeTag(&sb, "Date", tags.Date)
+	writeTag(&sb, "Round", tags.Round)
+	writeTag(&sb, "White", tags.White)
+	writeTag(&sb, "Black", tags.Black)
+	writeTag(&sb, "Result", result)
+	// games that do not start from the initial position need the FEN to be replayed
+	if record.StartFEN != StartFEN {
+		writeTag(&sb, "SetUp", "1")
+		writeTag(&sb, "FEN", record.StartFEN)
+	}
+	sb.WriteByte('\n')
+
+	// movetext, with the number of the move before every white move
+	b := &Board{}
+	if err := b.LoadFEN(record.StartFEN); err != nil {
+		return err
+	}
+	moveNumber, side := b.fullmoveNumber, b.sideToMove
+	var tokens []string
+	for i, san := range record.Moves {
+		if side == White {
+			tokens = append(tokens, fmt.Sprintf("%d.", moveNumber))
+		} else if i == 0 {
+			tokens = append(tokens, fmt.Sprintf("%d...", moveNumber))
+		}
+		tokens = append(tokens, san)
+		if side == Black {
+			moveNumber++
+		}
+		side = side.Opponent()
+	}
+	tokens = append(tokens, result)
+	writeMovetext(&sb, tokens)
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeTag(sb *strings.Builder, name, value string) {
+	// quotes and backslashes inside the value have to be escaped
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	sb.WriteString(fmt.Sprintf("[%s \"%s\"]\n", name, value))
+}
+
+// Writes the tokens of the movetext separated by spaces, in lines no longer
+// than pgnLineLength
+func writeMovetext(sb *strings.Builder, tokens []string) {
+	lineLength := 0
+	for _, tok := range tokens {
+		if lineLength > 0 && lineLength+1+len(tok) > pgnLineLength {
+			sb.WriteByte('\n')
+			lineLength = 0
+		} else if lineLength > 0 {
+			sb.WriteByte(' ')
+			lineLength++
+		}
+		sb.WriteString(tok)
+		lineLength += len(tok)
+	}
+	sb.WriteByte('\n')
+}
+
+// A PGNGame is a game read from a PGN file
+type PGNGame struct {
+	// tag pairs of the game, by name
+	Tags map[string]string
+	// moves of the main line of the game in SAN. Comments and variations are
+	// not kept
+	Moves []string
+	// result written at the end of the movetext, "*" if there is none
+	Result string
+}
+
+// Returns true if the token is one of the results that end a game
+func isPGNResult(tok string) bool {
+	return tok == "1-0" || tok == "0-1" || tok == "1/2-1/2" || tok == "*"
+}
+
+// Reads all the games of a PGN file. Comments, variations and numeric
+// annotation glyphs are skipped, only the main line of each game is kept
+func ParsePGN(r io.Reader) ([]PGNGame, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+
+	var games []PGNGame
+	game := PGNGame{Tags: map[string]string{}, Result: "*"}
+	hasContent := false
+	// the current game is finished, and a new one starts
+	endGame := func() {
+		if hasContent {
+			games = append(games, game)
+		}
+		game = PGNGame{Tags: map[string]string{}, Result: "*"}
+		hasContent = false
+	}
+	// depth of the variation being read, 0 for the main line
+	depth := 0
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == ';' || (c == '%' && (i == 0 || s[i-1] == '\n')):
+			// comments and escaped lines go to the end of the line
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated comment", ErrInvalidPGN)
+			}
+			i += end + 1
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("%w: unexpected end of variation", ErrInvalidPGN)
+			}
+			i++
+		case c == '[' && depth == 0:
+			// tags after the movetext belong to the next game
+			if len(game.Moves) > 0 {
+				endGame()
+			}
+			name, value, n, err := parsePGNTag(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			game.Tags[name] = value
+			hasContent = true
+			i += n
+		default:
+			// a token goes until the next space or delimiter
+			j := i
+			for j < len(s) && strings.IndexByte(" \t\r\n{}();[", s[j]) < 0 {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidPGN, c)
+			}
+			tok := s[i:j]
+			i = j
+			if depth > 0 || tok[0] == '$' {
+				// variations and annotation glyphs are skipped
+				continue
+			}
+			if isPGNResult(tok) {
+				game.Result = tok
+				hasContent = true
+				endGame()
+				continue
+			}
+			// move numbers can be attached to the move, like "1.e4". Castling
+			// written with zeros, like "0-0", has no dots
+			tok = moveNumberRegexp.ReplaceAllString(tok, "")
+			if tok != "" {
+				game.Moves = append(game.Moves, tok)
+				hasContent = true
+			}
+		}
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("%w: unterminated variation", ErrInvalidPGN)
+	}
+	endGame()
+	return games, nil
+}
+
+// Parses the tag pair at the beginning of s, like [Event "Casual game"].
+// Returns the name and the value of the tag and the length of the tag pair
+func parsePGNTag(s string) (name, value string, n int, err error) {
+	end := strings.IndexByte(s, ']')
+	open := strings.IndexByte(s, '"')
+	if end < 0 || open < 0 || open > end {
+		return "", "", 0, fmt.Errorf("%w: malformed tag pair", ErrInvalidPGN)
+	}
+	name = strings.TrimSpace(s[1:open])
+	// the value ends at the first quote that is not escaped
+	var sb strings.Builder
+	i := open + 1
+	for ; i < len(s) && s[i] != '"'; i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	end = strings.IndexByte(s[i:], ']')
+	if name == "" || i == len(s) || end < 0 {
+		return "", "", 0, fmt.Errorf("%w: malformed tag pair", ErrInvalidPGN)
+	}
+	return name, sb.String(), i + end + 1, nil
+}
+
+// Returns the positions of the game in FEN, from the position the game starts
+// at to the position after the last move. Returns an error if any of the moves
+// is not legal
+func (g PGNGame) GetPositions() ([]string, error) {
+	fen := StartFEN
+	if f, exists := g.Tags["FEN"]; exists {
+		fen = f
+	}
+	b := &Board{}
+	if err := b.LoadFEN(fen); err != nil {
+		return nil, err
+	}
+	positions := []string{b.GetFEN()}
+	for i, san := range g.Moves {
+		if err := b.MoveSAN(san); err != nil {
+			return nil, fmt.Errorf("move %d: %w", i+1, err)
+		}
+		positions = append(positions, b.GetFEN())
+	}
+	return positions, nil
+}
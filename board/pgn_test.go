@@ -0,0 +1,196 @@
+package board
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testPGN = `[Event "Casual \"blitz\" game"]
+[Site "C:\\games"]
+[White "Doe, John"]
+[Result "*"]
+
+1. e4 {best by test} e5 2. Nf3 (2. f4 exf4 (2... d5 3. exd5) 3. Nf3) 2...Nc6 $1
+3.Bc4 Bc5 ; the Italian game
+4. 0-0 Nf6 5. d3 0-0 *
+
+[Event "Second"]
+[Result "1/2-1/2"]
+
+1. d4 d5 2. Nc3 Nc6 3. Bf4 Bf5 4. Qd2 Qd7 5. 0-0-0 0-0-0 1/2-1/2
+`
+
+func TestParsePGN(t *testing.T) {
+	games, err := ParsePGN(strings.NewReader(testPGN))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		tags   map[string]string
+		moves  string
+		result string
+		fen    string
+	}{
+		{
+			map[string]string{"Event": `Casual "blitz" game`, "Site": `C:\games`, "White": "Doe, John", "Result": "*"},
+			"e4 e5 Nf3 Nc6 Bc4 Bc5 0-0 Nf6 d3 0-0",
+			"*",
+			"r1bq1rk1/pppp1ppp/2n2n2/2b1p3/2B1P3/3P1N2/PPP2PPP/RNBQ1RK1 w - - 1 6",
+		},
+		{
+			map[string]string{"Event": "Second", "Result": "1/2-1/2"},
+			"d4 d5 Nc3 Nc6 Bf4 Bf5 Qd2 Qd7 0-0-0 0-0-0",
+			"1/2-1/2",
+			"2kr1bnr/pppqpppp/2n5/3p1b2/3P1B2/2N5/PPPQPPPP/2KR1BNR w - - 8 6",
+		},
+	}
+	if len(games) != len(want) {
+		t.Fatalf("got %d games, want %d", len(games), len(want))
+	}
+	for i, g := range games {
+		for name, value := range want[i].tags {
+			if g.Tags[name] != value {
+				t.Errorf("game %d: got tag %s %q, want %q", i+1, name, g.Tags[name], value)
+			}
+		}
+		if moves := strings.Join(g.Moves, " "); moves != want[i].moves {
+			t.Errorf("game %d: got moves %q, want %q", i+1, moves, want[i].moves)
+		}
+		if g.Result != want[i].result {
+			t.Errorf("game %d: got result %q, want %q", i+1, g.Result, want[i].result)
+		}
+		positions, err := g.GetPositions()
+		if err != nil {
+			t.Fatalf("game %d: %v", i+1, err)
+		}
+		if fen := positions[len(positions)-1]; fen != want[i].fen {
+			t.Errorf("game %d: got final position %s, want %s", i+1, fen, want[i].fen)
+		}
+	}
+}
+
+func TestParsePGNInvalid(t *testing.T) {
+	tests := []struct{ name, pgn string }{
+		{"unterminated comment", "1. e4 {comment e5 *"},
+		{"unexpected end of variation", "1. e4 e5) *"},
+		{"unterminated variation", "1. e4 (1. d4 d5 *"},
+		{"malformed tag pair", "[Event Casual]\n\n1. e4 *"},
+	}
+	for _, tt := range tests {
+		if _, err := ParsePGN(strings.NewReader(tt.pgn)); !errors.Is(err, ErrInvalidPGN) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, ErrInvalidPGN)
+		}
+	}
+}
+
+func TestWritePGN(t *testing.T) {
+	tags := PGNTags{
+		Event: "Test",
+		Site:  "?",
+		Date:  "2024.01.01",
+		Round: "1",
+		White: `A "quoted" \ name`,
+		Black: "B",
+	}
+	tests := []struct {
+		name  string
+		fen   string
+		moves []string
+		pgn   string
+	}{
+		{
+			"fool's mate",
+			StartFEN,
+			[]string{"f3", "e5", "g4", "Qh4#"},
+			`[Event "Test"]
+[Site "?"]
+[Date "2024.01.01"]
+[Round "1"]
+[White "A \"quoted\" \\ name"]
+[Black "B"]
+[Result "0-1"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+`,
+		},
+		{
+			"black starts from a position",
+			"4k3/8/8/8/8/8/4P3/4K3 b - - 0 10",
+			[]string{"Kd7", "e4"},
+			`[Event "Test"]
+[Site "?"]
+[Date "2024.01.01"]
+[Round "1"]
+[White "A \"quoted\" \\ name"]
+[Black "B"]
+[Result "*"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 b - - 0 10"]
+
+10... Kd7 11. e4 *
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Board{}
+			if err := b.LoadFEN(tt.fen); err != nil {
+				t.Fatal(err)
+			}
+			for _, san := range tt.moves {
+				if err := b.MoveSAN(san); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var sb strings.Builder
+			if err := b.WritePGN(&sb, tags); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.pgn {
+				t.Errorf("got\n%s\nwant\n%s", sb.String(), tt.pgn)
+			}
+		})
+	}
+}
+
+// A game written in PGN is read back with the same moves, in lines no longer
+// than pgnLineLength
+func TestWritePGNRoundTrip(t *testing.T) {
+	games, err := ParsePGN(strings.NewReader(testPGN))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Board{}
+	if err := b.LoadFEN(StartFEN); err != nil {
+		t.Fatal(err)
+	}
+	// long enough to be split in several lines
+	moves := append(games[0].Moves, "Bg5", "d6", "Nc3", "Bg4", "h3", "Bh5", "g4", "Bg6", "a3", "a6", "b4", "Ba7")
+	for _, san := range moves {
+		if err := b.MoveSAN(san); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var sb strings.Builder
+	if err := b.WritePGN(&sb, NewPGNTags()); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if len(line) > pgnLineLength {
+			t.Errorf("line longer than %d: %q", pgnLineLength, line)
+		}
+	}
+	read, err := ParsePGN(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 1 {
+		t.Fatalf("got %d games, want 1", len(read))
+	}
+	got := strings.Join(read[0].Moves, " ")
+	want := strings.Join(b.GetGameRecord().Moves, " ")
+	if got != want {
+		t.Errorf("got moves %q, want %q", got, want)
+	}
+}
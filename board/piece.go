@@ -2,8 +2,6 @@ package board
 
 import (
 	"ChessEngine/globals"
-	"log"
-	"math"
 )
 
 const (
@@ -91,20 +89,29 @@ type Piece uint16
 
 type PieceType uint8
 
-type PiecePosition uint8
+// A Color represents one of the two players. It matches the parity of the
+// PieceType values, white pieces being even and black pieces being odd
+type Color uint8
 
-func (pos PiecePosition) getX() int {
-	return int(pos % globals.TableDim)
-}
+const (
+	White Color = iota
+	Black
+)
 
-func (pos PiecePosition) getY() int {
-	return int(pos / globals.TableDim)
+// Returns the color of the other player
+func (c Color) Opponent() Color {
+	return c ^ 1
 }
 
-func (pos PiecePosition) isOutOfBounds() bool {
-	return pos > 63
+func (c Color) String() string {
+	if c == White {
+		return "white"
+	}
+	return "black"
 }
 
+type PiecePosition uint8
+
 func NewPiece(pt PieceType, pp int) *Piece {
 	p := Piece(pp<<8 | int(pt))
 	return &p
@@ -114,111 +121,75 @@ func (piece *Piece) MoveTo(to int) {
 	piece.setPosition(PiecePosition(to))
 }
 
+func (p *Piece) GetColor() Color {
+	return Color(p.GetPieceType() % 2)
+}
+
 func AreSameColor(p1, p2 *Piece) bool {
 	// white pieces are even numbers, and black pieces are odd numbers
 	// to check if they are the same color, we just have to check if both are
 	// either even or odd
-	return (p1.getPieceType()%2 == p2.getPieceType()%2)
+	return (p1.GetPieceType()%2 == p2.GetPieceType()%2)
 }
 
-func (p *Piece) isIllegalMove(board *Board, newpos int) bool {
-	// current and new piece positions
-	ppos := PiecePosition(p.getPosition())
-	npos := PiecePosition(newpos)
-
-	px, py := ppos.getX(), ppos.getY()
-	nx, ny := npos.getX(), npos.getY()
-
-	xdiff := math.Abs(float64(px - nx))
-	ydiff := math.Abs(float64(py - ny))
-
-	switch p.getPieceType() {
-	case WhitePawn, BlackPawn:
-		// new position is out of bounds
-		if npos.isOutOfBounds() {
-			return true
-		}
-
-		// pawn in left side moves diagonal and goes to the right side
-		if ((px == 0) && (nx == 7)) ||
-			// pawn in right sido moves diagonal and goes to the left side
-			((px == 7) && (nx == 0)) {
-			return true
-		}
-
-		// pawn can only move diagonal if and only if another piece from the oposite color is
-		// in a diagonal and the new move is a giagonal one
-		if xdiff == 1 && ydiff == 1 {
-			if !board.isThereAPieceAt(newpos) {
-				return true
-			} else {
-				// we already checked if there's a piece there, so we do not have to check the error here
-				np, _ := board.GetPieceAt(nx, ny)
-				return AreSameColor(p, np)
-			}
-		}
-		// a pawn can only move two steps if its in the orignal state
-		if ydiff == 2 {
-			// get y axis of the piece (row)
-			return (py < 6) && (py > 1)
-		}
-		// and obviously, a pawn cannot move forward if any piece is in front of him
-		if (ydiff == 1 || ydiff == 2) && board.isThereAPieceAt(newpos) {
-			return true
-		}
-		// otherwise, its a good move
-		return false
-
-	case WhiteKnight, BlackKnight:
-
-		// check out of bounds
-		if npos.isOutOfBounds() {
-			return true
-		}
-
-		// check if knight overflows from the bottom, top, left or right
-		if (px <= 1 && nx >= 6) || (px >= 6 && nx <= 1) ||
-			(py <= 1 && ny >= 6) || (py >= 6 && ny <= 1) {
-			return true
-		}
-
-		// check if there's a piece in the new position
-		if board.isThereAPieceAt(newpos) {
-			np, _ := board.GetPieceAt(nx, ny)
-			if AreSameColor(p, np) {
-				return true
-			}
+// Returns the positions the piece can legally move to, that is, the movements
+// that do not leave its own king in check
+func (p *Piece) GetAvailableMovements(board *Board) (newPositions []int) {
+	for _, npos := range p.getPseudoLegalMovements(board) {
+		if !board.leavesKingInCheck(p, npos) {
+			newPositions = append(newPositions, npos)
 		}
+	}
+	// castling already checks that the king does not go through check
+	if pt := p.GetPieceType(); pt == WhiteKing || pt == BlackKing {
+		newPositions = append(newPositions, board.getCastlingMovements(p)...)
+	}
+	return
+}
 
-		return false
-
-	case WhiteBishop, BlackBishop:
-
-		// check out of bounds
-		if npos.isOutOfBounds() {
+// Returns true if the piece can legally move to the position pos
+func (p *Piece) canMoveTo(board *Board, pos int) bool {
+	for _, npos := range p.GetAvailableMovements(board) {
+		if npos == pos {
 			return true
 		}
+	}
+	return false
+}
 
-		return false
-	default:
-		log.Println("Not implemented")
-		return false
+// Returns the positions the piece can move to following its movement rules,
+// without taking into account if its own king ends up in check
+func (p *Piece) getPseudoLegalMovements(board *Board) []int {
+	pos, pt := p.getPosition(), p.GetPieceType()
+	if pt == WhitePawn || pt == BlackPawn {
+		return p.getPawnMovements(board).positions()
 	}
+	// pieces can go to any cell they attack, unless there is an ally there
+	return (board.attacksFrom(pt, pos) &^ board.colorTables[p.GetColor()]).positions()
 }
 
-func (p *Piece) GetAvailableMovements(board *Board) (newPositions []int) {
-	ppos := int(p.getPosition())
-	for _, m := range Movements[p.getPieceType()] {
-		for r := 1; r <= m.Limit; r++ {
-			npos := ppos + m.Move(r)
-			// check position validity
-			if !p.isIllegalMove(board, npos) {
-				// if it does not overflow, we append it
-				newPositions = append(newPositions, npos)
-			}
+// Returns the cells a pawn can move to: one cell forward if it is empty, two
+// cells forward from its initial row if both are empty, and the cells it attacks
+// if there is an enemy piece or it can capture en passant there
+func (p *Piece) getPawnMovements(board *Board) (targets table) {
+	pos, c := p.getPosition(), p.GetColor()
+	forward, startRow := -globals.TableDim, globals.TableDim-2
+	if c == Black {
+		forward, startRow = globals.TableDim, 1
+	}
+	empty := ^board.tableCurrentFrame
+	// a pawn in the last row has already been promoted, so it cannot go further
+	if npos := pos + forward; npos >= 0 && npos < globals.TableDim*globals.TableDim && empty.has(npos) {
+		targets.set(npos)
+		if pos/globals.TableDim == startRow && empty.has(npos+forward) {
+			targets.set(npos + forward)
 		}
 	}
-	return
+	enemies := board.colorTables[c.Opponent()]
+	if board.enPassant != noEnPassant && board.canCaptureEnPassant(p, board.enPassant) {
+		enemies.set(board.enPassant)
+	}
+	return targets | pawnAttacks[c][pos]&enemies
 }
 
 func (p *Piece) GetLogicalPosition() (logX int, logY int) {
@@ -232,9 +203,9 @@ func (p *Piece) getPosition() int {
 
 func (p *Piece) setPosition(position PiecePosition) {
 	newpos := (uint16(position) << 8) // xxxxxxxx11111111
-	*p = Piece(newpos | uint16(p.getPieceType()))
+	*p = Piece(newpos | uint16(p.GetPieceType()))
 }
 
-func (p *Piece) getPieceType() PieceType {
+func (p *Piece) GetPieceType() PieceType {
 	return PieceType(uint16(*p) & pieceMask)
 }
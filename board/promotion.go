@@ -0,0 +1,49 @@
+package board
+
+import (
+	"errors"
+
+	"ChessEngine/globals"
+)
+
+// NoPromotion is the promotion piece of any move that is not a pawn promotion.
+// A pawn is never promoted to a pawn, so it is the zero value, and a Move
+// written without a promotion piece is not a promotion
+const NoPromotion PieceType = 0
+
+var ErrInvalidPromotion = errors.New("invalid promotion piece")
+
+// Returns the pieces a pawn of color c can be promoted to, in the order they
+// are offered to the player
+func GetPromotionPieces(c Color) []PieceType {
+	if c == White {
+		return []PieceType{WhiteQueen, WhiteRook, WhiteBishop, WhiteKnight}
+	}
+	return []PieceType{BlackQueen, BlackRook, BlackBishop, BlackKnight}
+}
+
+// Returns true if the move m promotes a pawn, that is, if it moves a pawn to
+// the last row of the board
+func (board *Board) IsPromotion(m Move) bool {
+	p, exists := board.pieceAt(m.From)
+	if !exists {
+		return false
+	}
+	pt, y := p.GetPieceType(), m.To/globals.TableDim
+	return (pt == WhitePawn && y == 0) || (pt == BlackPawn && y == globals.TableDim-1)
+}
+
+// Returns true if a pawn of color c can be promoted to a piece of type pt
+func isValidPromotion(c Color, pt PieceType) bool {
+	for _, ppt := range GetPromotionPieces(c) {
+		if ppt == pt {
+			return true
+		}
+	}
+	return false
+}
+
+// Changes the type of the piece to pt, keeping its position
+func (p *Piece) promote(pt PieceType) {
+	*p = Piece(uint16(p.getPosition())<<8 | uint16(pt))
+}
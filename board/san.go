@@ -0,0 +1,189 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ChessEngine/globals"
+)
+
+var (
+	ErrInvalidSAN   = errors.New("invalid SAN move")
+	ErrAmbiguousSAN = errors.New("ambiguous SAN move")
+)
+
+// Matches a SAN move that is not castling, without check indicators. The groups
+// are: the piece, the column and the row the piece comes from, the capture, the
+// cell the piece goes to and the promotion piece
+var sanRegexp = regexp.MustCompile(`^([NBRQK])?([a-h])?([1-8])?(x)?([a-h][1-8])(?:=([NBRQ]))?$`)
+
+// Returns the move m in Standard Algebraic Notation, like "Nf3", "exd5", "O-O"
+// or "e8=Q+". It has to be called before the move is made
+func (board *Board) ToSAN(m Move) string {
+	return board.toSANWithoutCheck(m) + board.checkSuffix(m)
+}
+
+// Returns the SAN of the move without the check or checkmate indicator
+func (board *Board) toSANWithoutCheck(m Move) string {
+	p, _ := board.pieceAt(m.From)
+	pt := p.GetPieceType()
+	var sb strings.Builder
+
+	switch {
+	case (pt == WhiteKing || pt == BlackKing) && m.To-m.From == 2:
+		sb.WriteString("O-O")
+	case (pt == WhiteKing || pt == BlackKing) && m.From-m.To == 2:
+		sb.WriteString("O-O-O")
+	default:
+		capture := board.isThereAPieceAt(m.To) || board.isEnPassantCapture(m.From, m.To)
+		if pt == WhitePawn || pt == BlackPawn {
+			// pawn captures are identified by the column the pawn comes from
+			if capture {
+				sb.WriteByte(SquareName(m.From)[0])
+			}
+		} else {
+			// pieces are always written with the uppercase (white) letter
+			sb.WriteByte(pieceLetters[pt&^1])
+			sb.WriteString(board.disambiguation(m))
+		}
+		if capture {
+			sb.WriteByte('x')
+		}
+		sb.WriteString(SquareName(m.To))
+		if m.Promotion != NoPromotion {
+			sb.WriteByte('=')
+			sb.WriteByte(pieceLetters[m.Promotion&^1])
+		}
+	}
+	return sb.String()
+}
+
+// Returns "+" if the move gives check, "#" if it gives checkmate and an empty
+// string otherwise
+func (board *Board) checkSuffix(m Move) string {
+	// check and checkmate are found by trying the move in a copy of the board
+	b := board.clone()
+	b.makeMove(m)
+	if !b.IsInCheck(b.sideToMove) {
+		return ""
+	}
+	if b.HasLegalMoves(b.sideToMove) {
+		return "+"
+	}
+	return "#"
+}
+
+// Returns what has to be added to the SAN of the move m so it is not ambiguous:
+// the column, the row or the cell the piece comes from if other pieces of the
+// same type can move to the same cell
+func (board *Board) disambiguation(m Move) string {
+	p, _ := board.pieceAt(m.From)
+	ambiguous, sameX, sameY := false, false, false
+	for _, other := range board.getPiecesOf(p.GetColor()) {
+		pos := other.getPosition()
+		if pos == m.From || other.GetPieceType() != p.GetPieceType() {
+			continue
+		}
+		for _, npos := range other.GetAvailableMovements(board) {
+			if npos == m.To {
+				ambiguous = true
+				sameX = sameX || pos%globals.TableDim == m.From%globals.TableDim
+				sameY = sameY || pos/globals.TableDim == m.From/globals.TableDim
+			}
+		}
+	}
+	name := SquareName(m.From)
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameX:
+		return name[:1]
+	case !sameY:
+		return name[1:]
+	default:
+		return name
+	}
+}
+
+// Returns the legal move written in Standard Algebraic Notation for the player
+// to move. Returns ErrInvalidSAN if it does not match any legal move, and
+// ErrAmbiguousSAN if it matches more than one
+func (board *Board) ParseSAN(san string) (Move, error) {
+	s := normalizeSAN(san)
+	var matches []Move
+
+	if s == "O-O" || s == "O-O-O" {
+		for _, m := range board.GetLegalMoves() {
+			p, _ := board.pieceAt(m.From)
+			pt := p.GetPieceType()
+			if (pt == WhiteKing || pt == BlackKing) &&
+				((s == "O-O" && m.To-m.From == 2) || (s == "O-O-O" && m.From-m.To == 2)) {
+				matches = append(matches, m)
+			}
+		}
+	} else {
+		groups := sanRegexp.FindStringSubmatch(s)
+		if groups == nil {
+			return NoMove, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+		}
+		// pieces are compared by their white type, pawns have no letter
+		piece := WhitePawn
+		if groups[1] != "" {
+			piece, _ = letterToPieceType(groups[1][0])
+		}
+		to, _ := ParseSquare(groups[5])
+		promotion := NoPromotion
+		if groups[6] != "" {
+			promotion, _ = letterToPieceType(groups[6][0])
+		}
+		for _, m := range board.GetLegalMoves() {
+			from := SquareName(m.From)
+			p, _ := board.pieceAt(m.From)
+			if p.GetPieceType()&^1 != piece ||
+				m.To != to ||
+				(groups[2] != "" && groups[2][0] != from[0]) ||
+				(groups[3] != "" && groups[3][0] != from[1]) ||
+				(m.Promotion == NoPromotion) != (promotion == NoPromotion) ||
+				(m.Promotion != NoPromotion && m.Promotion&^1 != promotion) {
+				continue
+			}
+			matches = append(matches, m)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return NoMove, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+	case 1:
+		return matches[0], nil
+	default:
+		return NoMove, fmt.Errorf("%w: %q", ErrAmbiguousSAN, san)
+	}
+}
+
+// Makes the move written in Standard Algebraic Notation for the player to move.
+// Returns an error if it is not a legal move
+func (board *Board) MoveSAN(san string) error {
+	m, err := board.ParseSAN(san)
+	if err != nil {
+		return err
+	}
+	return board.Move(m)
+}
+
+// Returns the SAN move without check indicators and annotations, and with the
+// usual variations of the notation written in the standard way
+func normalizeSAN(san string) string {
+	san = strings.TrimRight(san, "+#!?")
+	san = strings.TrimSuffix(san, "e.p.")
+	// castling is sometimes written with zeros
+	san = strings.ReplaceAll(san, "0", "O")
+	// promotions are sometimes written without the equals sign, like "e8Q"
+	if n := len(san); n >= 3 && strings.IndexByte("QRBN", san[n-1]) >= 0 &&
+		san[n-2] >= '1' && san[n-2] <= '8' {
+		san = san[:n-1] + "=" + san[n-1:]
+	}
+	return san
+}
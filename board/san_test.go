@@ -0,0 +1,104 @@
+package board
+
+import (
+	"errors"
+	"testing"
+)
+
+var sanTests = []struct {
+	name string
+	fen  string
+	// the move in UCI notation, and in SAN
+	uci, san string
+}{
+	{"piece move", StartFEN, "g1f3", "Nf3"},
+	{"pawn move", StartFEN, "e2e4", "e4"},
+	{"disambiguation by column", "4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "a1d1", "Rad1"},
+	{"disambiguation by column (other piece)", "4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "h1d1", "Rhd1"},
+	{"disambiguation by row", "4k3/8/8/R7/8/8/4K3/R7 w - - 0 1", "a1a3", "R1a3"},
+	{"disambiguation by row (other piece)", "4k3/8/8/R7/8/8/4K3/R7 w - - 0 1", "a5a3", "R5a3"},
+	{"disambiguation by cell", "4k3/8/8/8/8/Q7/4K3/Q1Q5 w - - 0 1", "a1b2", "Qa1b2"},
+	{"pawn capture", "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2", "e4d5", "exd5"},
+	{"en passant", "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", "e5f6", "exf6"},
+	{"promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8q", "e8=Q"},
+	{"underpromotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8n", "e8=N"},
+	{"promotion capturing with check", "3r2k1/4P3/8/8/8/8/8/4K3 w - - 0 1", "e7d8q", "exd8=Q+"},
+	{"checkmate", "rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq g3 0 2", "d8h4", "Qh4#"},
+	{"kingside castling", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", "O-O"},
+	{"queenside castling", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1c1", "O-O-O"},
+	{"black queenside castling", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8c8", "O-O-O"},
+}
+
+func TestToSAN(t *testing.T) {
+	for _, tt := range sanTests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Board{}
+			if err := b.LoadFEN(tt.fen); err != nil {
+				t.Fatal(err)
+			}
+			m, err := b.ParseUCI(tt.uci)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if san := b.ToSAN(m); san != tt.san {
+				t.Errorf("got %s, want %s", san, tt.san)
+			}
+		})
+	}
+}
+
+func TestParseSAN(t *testing.T) {
+	tests := []struct{ name, fen, san, uci string }{
+		{"castling with zeros", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "0-0-0", "e1c1"},
+		{"promotion without equals sign", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8Q", "e7e8q"},
+		{"annotations", StartFEN, "Nf3!?", "g1f3"},
+		{"unneeded disambiguation", StartFEN, "Ng1f3", "g1f3"},
+		{"en passant suffix", "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", "exf6e.p.", "e5f6"},
+	}
+	for _, tt := range sanTests {
+		tests = append(tests, struct{ name, fen, san, uci string }{tt.name, tt.fen, tt.san, tt.uci})
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Board{}
+			if err := b.LoadFEN(tt.fen); err != nil {
+				t.Fatal(err)
+			}
+			m, err := b.ParseSAN(tt.san)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.String() != tt.uci {
+				t.Errorf("got %s, want %s", m, tt.uci)
+			}
+		})
+	}
+}
+
+func TestParseSANInvalid(t *testing.T) {
+	tests := []struct {
+		name, fen, san string
+		err            error
+	}{
+		{"ambiguous column", "4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "Rd1", ErrAmbiguousSAN},
+		{"ambiguous row", "4k3/8/8/R7/8/8/4K3/R7 w - - 0 1", "Ra3", ErrAmbiguousSAN},
+		{"ambiguous with column", "4k3/8/8/8/8/Q7/4K3/Q1Q5 w - - 0 1", "Qab2", ErrAmbiguousSAN},
+		{"illegal move", StartFEN, "Ke2", ErrInvalidSAN},
+		{"illegal pawn move", StartFEN, "e5", ErrInvalidSAN},
+		{"illegal castling", StartFEN, "O-O", ErrInvalidSAN},
+		{"missing promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8", ErrInvalidSAN},
+		{"promotion to king", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8=K", ErrInvalidSAN},
+		{"not a move", StartFEN, "hello", ErrInvalidSAN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Board{}
+			if err := b.LoadFEN(tt.fen); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := b.ParseSAN(tt.san); !errors.Is(err, tt.err) {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+
+	"ChessEngine/globals"
+)
+
+var ErrInvalidSquare = errors.New("invalid square")
+
+// Returns the name of the cell at position pos in algebraic notation. The cell 0
+// (top left corner) is a8 and the cell 63 (bottom right corner) is h1
+func SquareName(pos int) string {
+	file := byte('a' + pos%globals.TableDim)
+	rank := byte('0' + globals.TableDim - pos/globals.TableDim)
+	return string([]byte{file, rank})
+}
+
+// Returns the position of the cell named s in algebraic notation, like "e4"
+func ParseSquare(s string) (int, error) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSquare, s)
+	}
+	x := int(s[0] - 'a')
+	y := globals.TableDim - int(s[1]-'0')
+	return y*globals.TableDim + x, nil
+}
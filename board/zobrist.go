@@ -0,0 +1,72 @@
+package board
+
+import (
+	"math/rand"
+
+	"ChessEngine/globals"
+)
+
+// Zobrist hashing gives every position a 64-bit key, which is the xor of a
+// random number for each feature of the position: every piece at its cell, the
+// player to move, the castling rights and the column of the en passant cell.
+// Positions with the same pieces and the same state of the game have the same
+// key, and making a move only has to xor the numbers of what changes
+var (
+	pieceKeys     [12][64]uint64
+	sideKey       uint64
+	castlingKeys  [AllCastlingRights + 1]uint64
+	enPassantKeys [8]uint64
+)
+
+// seed of the random numbers, fixed so the keys are the same in every run
+const zobristSeed = 0x5eed
+
+func init() {
+	r := rand.New(rand.NewSource(zobristSeed))
+	for pt := range pieceKeys {
+		for pos := range pieceKeys[pt] {
+			pieceKeys[pt][pos] = r.Uint64()
+		}
+	}
+	sideKey = r.Uint64()
+	for i := range castlingKeys {
+		castlingKeys[i] = r.Uint64()
+	}
+	for i := range enPassantKeys {
+		enPassantKeys[i] = r.Uint64()
+	}
+}
+
+// Returns the Zobrist key of the position of the board. Two positions with the
+// same pieces, player to move, castling rights and en passant captures have the
+// same key. The move counters are not part of the key
+func (board *Board) GetHash() uint64 {
+	return board.hash
+}
+
+// Returns the key of the position computed from scratch, instead of being
+// updated move by move
+func (board *Board) computeHash() (hash uint64) {
+	for _, p := range board.GetPieces() {
+		hash ^= pieceKeys[p.GetPieceType()][p.getPosition()]
+	}
+	return hash ^ board.stateHash()
+}
+
+// Returns the part of the key that is not given by the pieces: the player to
+// move, the castling rights and the en passant cell
+func (board *Board) stateHash() (hash uint64) {
+	if board.sideToMove == Black {
+		hash ^= sideKey
+	}
+	hash ^= castlingKeys[board.castlingRights]
+	// the en passant cell only tells a position apart if a pawn can capture
+	// there, otherwise the position is the same
+	if board.enPassant != noEnPassant {
+		pawn := WhitePawn + PieceType(board.sideToMove)
+		if pawnAttacks[board.sideToMove.Opponent()][board.enPassant]&board.pieceTables[pawn] != 0 {
+			hash ^= enPassantKeys[board.enPassant%globals.TableDim]
+		}
+	}
+	return
+}
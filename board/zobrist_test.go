@@ -0,0 +1,89 @@
+package board
+
+import "testing"
+
+// Checks that the key updated move by move is the one computed from scratch, at
+// every node of the tree of moves depth moves deep
+func checkHash(t *testing.T, b *Board, depth int) {
+	if hash := b.computeHash(); b.GetHash() != hash {
+		t.Fatalf("%s: got key %x, want %x", b.GetFEN(), b.GetHash(), hash)
+	}
+	if depth == 0 {
+		return
+	}
+	for _, m := range b.GetLegalMoves() {
+		hash := b.GetHash()
+		u := b.makeMove(m)
+		checkHash(t, b, depth-1)
+		b.unmakeMove(u)
+		if b.GetHash() != hash {
+			t.Fatalf("%s: unmaking %s changed the key", b.GetFEN(), m)
+		}
+	}
+}
+
+func TestHashIncremental(t *testing.T) {
+	for _, tt := range perftTests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Board{}
+			if err := b.LoadFEN(tt.fen); err != nil {
+				t.Fatal(err)
+			}
+			checkHash(t, b, 3)
+		})
+	}
+}
+
+func TestHashTranspositions(t *testing.T) {
+	tests := []struct {
+		name   string
+		fen    string
+		a, b   []string
+		sameAs bool
+	}{
+		{"move order", StartFEN, []string{"e2e4", "e7e5", "g1f3"}, []string{"g1f3", "e7e5", "e2e4"}, true},
+		{"knights back home", StartFEN, []string{"g1f3", "g8f6", "f3g1", "f6g8"}, nil, true},
+		{"castling rights", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", []string{"e1f1", "e8f8", "f1e1", "f8e8"}, nil, false},
+		// the en passant cell only counts if a pawn can capture there
+		{"en passant", "4k3/8/8/8/5p2/8/4P3/4K3 w - - 0 1", []string{"e2e4"}, []string{"e2e3", "e8d8", "e3e4", "d8d7", "e1d1", "d7e8", "d1e1"}, false},
+		{"no en passant", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", []string{"e2e4"}, []string{"e2e3", "e8d8", "e3e4", "d8d7", "e1d1", "d7e8", "d1e1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := &Board{}, &Board{}
+			for _, game := range []struct {
+				board *Board
+				moves []string
+			}{{a, tt.a}, {b, tt.b}} {
+				if err := game.board.LoadFEN(tt.fen); err != nil {
+					t.Fatal(err)
+				}
+				for _, m := range game.moves {
+					if err := game.board.MoveUCI(m); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if same := a.GetHash() == b.GetHash(); same != tt.sameAs {
+				t.Errorf("%s and %s: got same key %v, want %v", a.GetFEN(), b.GetFEN(), same, tt.sameAs)
+			}
+		})
+	}
+}
+
+func TestHashSideToMove(t *testing.T) {
+	// the same pieces with another player to move are another position
+	white, black := &Board{}, &Board{}
+	if err := white.LoadFEN("4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := black.LoadFEN("4k3/8/8/8/8/8/4P3/4K3 b - - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	if white.GetHash() == black.GetHash() {
+		t.Errorf("got the same key %x with white and black to move", white.GetHash())
+	}
+	if white.GetHash()^black.GetHash() != sideKey {
+		t.Errorf("keys differ by %x, want the side key %x", white.GetHash()^black.GetHash(), sideKey)
+	}
+}
@@ -0,0 +1,36 @@
+// Command engine runs the chess engine without a window, so it can be played by
+// chess GUIs and tournament managers through the standard input and output
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"ChessEngine/transposition"
+	"ChessEngine/uci"
+	"ChessEngine/xboard"
+)
+
+func main() {
+
+	protocol := flag.String("protocol", "uci", "protocol spoken with the GUI: uci, or xboard for the Chess Engine Communication Protocol")
+	hash := flag.Int("hash", transposition.DefaultSize, "size of the transposition table, in megabytes")
+	flag.Parse()
+
+	if *hash < 1 || *hash > transposition.MaxSize {
+		log.Fatalf("invalid -hash %d, it has to be between 1 and %d\n", *hash, transposition.MaxSize)
+	}
+	var err error
+	switch *protocol {
+	case "uci":
+		err = uci.Run(os.Stdin, os.Stdout, *hash)
+	case "xboard":
+		err = xboard.Run(os.Stdin, os.Stdout, *hash)
+	default:
+		log.Fatalf("unknown protocol %q, it has to be uci or xboard\n", *protocol)
+	}
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+}
@@ -0,0 +1,88 @@
+// Package evaluation scores board.Board positions without searching them
+package evaluation
+
+import (
+	"fmt"
+	"strings"
+
+	"ChessEngine/board"
+	"ChessEngine/globals"
+)
+
+// A Term is the contribution of one feature of the position to its score, in
+// centipawns, from the point of view of White. The contribution is different
+// in the middlegame and in the endgame
+type Term struct {
+	Middlegame, Endgame int
+}
+
+// Returns the value of the term at the given game phase, interpolating between
+// its middlegame value (at maxPhase) and its endgame value (at 0)
+func (t Term) Taper(phase int) int {
+	return (t.Middlegame*phase + t.Endgame*(maxPhase-phase)) / maxPhase
+}
+
+// A Breakdown is the score of a position split by the terms it is made of
+type Breakdown struct {
+	// value of the pieces of each player
+	Material Term
+	// bonus of the pieces for the cells they are at
+	PieceSquare Term
+	// from maxPhase, with every piece on the board, down to 0, with only kings
+	// and pawns
+	Phase int
+	// sum of every term, tapered by the phase, from the point of view of the
+	// player to move
+	Score int
+}
+
+func (bd Breakdown) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-14s %10s %10s %10s\n", "term", "middlegame", "endgame", "tapered"))
+	for _, t := range []struct {
+		name string
+		term Term
+	}{
+		{"material", bd.Material},
+		{"piece-square", bd.PieceSquare},
+	} {
+		sb.WriteString(fmt.Sprintf("%-14s %10d %10d %10d\n", t.name, t.term.Middlegame, t.term.Endgame, t.term.Taper(bd.Phase)))
+	}
+	sb.WriteString(fmt.Sprintf("phase %d/%d, score %d for the player to move\n", bd.Phase, maxPhase, bd.Score))
+	return sb.String()
+}
+
+// Returns the score of the position of the board, in centipawns, from the point
+// of view of the player to move
+func Evaluate(b *board.Board) int {
+	return GetBreakdown(b).Score
+}
+
+// Returns the score of the position of the board split by terms
+func GetBreakdown(b *board.Board) (bd Breakdown) {
+	for _, p := range b.GetPieces() {
+		pt := p.GetPieceType()
+		x, y := p.GetLogicalPosition()
+		// black pieces use the tables upside down
+		sign := 1
+		if p.GetColor() == board.Black {
+			y = globals.TableDim - 1 - y
+			sign = -1
+		}
+		pos := y*globals.TableDim + x
+		bd.Material.Middlegame += sign * MiddlegameValues[pt]
+		bd.Material.Endgame += sign * EndgameValues[pt]
+		bd.PieceSquare.Middlegame += sign * middlegameTables[pt][pos]
+		bd.PieceSquare.Endgame += sign * endgameTables[pt][pos]
+		bd.Phase += phaseWeights[pt]
+	}
+	// promoted pieces can take the phase over its max
+	if bd.Phase > maxPhase {
+		bd.Phase = maxPhase
+	}
+	bd.Score = bd.Material.Taper(bd.Phase) + bd.PieceSquare.Taper(bd.Phase)
+	if b.GetSideToMove() == board.Black {
+		bd.Score = -bd.Score
+	}
+	return
+}
@@ -0,0 +1,57 @@
+package evaluation
+
+import (
+	"testing"
+
+	"ChessEngine/board"
+)
+
+func TestEvaluate(t *testing.T) {
+	b := &board.Board{}
+	if err := b.LoadFEN(board.StartFEN); err != nil {
+		t.Fatal(err)
+	}
+	bd := GetBreakdown(b)
+	if bd.Score != 0 || bd.Phase != maxPhase {
+		t.Errorf("initial position: got score %d and phase %d, want 0 and %d", bd.Score, bd.Phase, maxPhase)
+	}
+}
+
+// A position and the same one with the colors swapped have the same score for
+// the player to move
+func TestEvaluateSymmetry(t *testing.T) {
+	tests := []struct{ fen, mirrored string }{
+		{
+			"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+			"r3k2r/pppbbppp/2n2q1P/1P2p3/3pn3/BN2PNP1/P1PPQPB1/R3K2R b KQkq - 0 1",
+		},
+		{
+			"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+			"8/4p1p1/8/1r3P1K/kp5R/3P4/2P5/8 b - - 0 1",
+		},
+	}
+	for _, tt := range tests {
+		b, mirrored := &board.Board{}, &board.Board{}
+		if err := b.LoadFEN(tt.fen); err != nil {
+			t.Fatal(err)
+		}
+		if err := mirrored.LoadFEN(tt.mirrored); err != nil {
+			t.Fatal(err)
+		}
+		if s, ms := Evaluate(b), Evaluate(mirrored); s != ms {
+			t.Errorf("%s: got %d, and %d for the mirrored position", tt.fen, s, ms)
+		}
+	}
+}
+
+func TestEvaluateMaterial(t *testing.T) {
+	b := &board.Board{}
+	// white is a queen up
+	if err := b.LoadFEN("rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	bd := GetBreakdown(b)
+	if bd.Material.Middlegame != MiddlegameValues[board.WhiteQueen] || bd.Score >= 0 {
+		t.Errorf("got material %d and score %d for black", bd.Material.Middlegame, bd.Score)
+	}
+}
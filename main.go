@@ -3,58 +3,245 @@ package main
 import (
 	"ChessEngine/board"
 	"ChessEngine/globals"
+	"ChessEngine/render"
+	"ChessEngine/search"
+	"ChessEngine/transposition"
 	"ChessEngine/utils"
 
+	"flag"
+	"fmt"
+	"image/color"
 	"log"
+	"os"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 type App struct {
 	Board *board.Board
+	// Result of the game. Once the game has ended, no more moves are accepted
+	Result board.Result
+	// Piece selected by the player, nil if there is none, and the cells it can
+	// move to
+	selected  *board.Piece
+	available []int
+	// Move of a pawn waiting for the player to choose the piece it is promoted to
+	promoting *board.Move
+	// File the game is saved to in PGN after every move. Empty if the game is
+	// not saved
+	pgnPath string
+	// Game being replayed. When replaying a game the board cannot be played
+	replay *Replay
+	// Color of the pieces moved by the computer, nil if two humans play
+	computer *board.Color
+	// Time the computer spends thinking each move
+	moveTime time.Duration
+	// Results of the previous searches of the computer
+	table *transposition.Table
+	// Receives the move of the computer while it is thinking, nil otherwise. The
+	// search runs in its own goroutine so the window keeps responding
+	thinking chan search.Result
 }
 
 // Update proceeds the game state.
 // Update is called every tick (1/60 [s] by default).
 func (app *App) Update() (err error) {
+	// The board shows the positions of the game being replayed
+	if app.replay != nil {
+		if app.replay.Update() {
+			if err := app.Board.LoadFEN(app.replay.GetPosition()); err != nil {
+				log.Println(err.Error())
+			}
+		}
+		return nil
+	}
+	// The computer is thinking, its move is made once it is found
+	if app.thinking != nil {
+		select {
+		case r := <-app.thinking:
+			app.thinking = nil
+			app.move(r.Move)
+		default:
+		}
+		return nil
+	}
+	// Ctrl+Z undoes the last move and Ctrl+Y makes it again
+	if ebiten.IsKeyPressed(ebiten.KeyControl) {
+		if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+			app.undo(app.Board.Undo)
+		} else if inpututil.IsKeyJustPressed(ebiten.KeyY) {
+			app.undo(app.Board.Redo)
+		}
+	}
+	// The game has ended, ignore any input
+	if app.Result != board.Ongoing {
+		return nil
+	}
+	// The player cannot move the pieces of the computer
+	if app.isComputerTurn() {
+		app.think()
+		return nil
+	}
 	// Check for mouse pressed events
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
 		var x, y int
 		x, y = ebiten.CursorPosition()
 		// Get piece in position xpos,ypos
 		xLog, yLog := utils.GetLogicalPosition(float64(x), float64(y))
+		// A pawn is waiting to be promoted, so the click chooses the piece
+		if app.promoting != nil {
+			app.choosePromotion(xLog, yLog)
+			return nil
+		}
 		p, err := app.Board.GetPieceAt(xLog, yLog)
-		available := app.Board.IsItAvailablePosition(xLog, yLog)
-		if err != nil && err == board.ErrNoPieceAtPos && !available {
-			app.Board.SetClicked(false)
-			app.Board.SetClickedAt(0, 0)
-			app.Board.ResetMovements()
+		available := app.isAvailable(yLog*globals.TableDim + xLog)
+		// only the pieces of the player to move can be selected
+		selectable := err == nil && p.GetColor() == app.Board.GetSideToMove()
+		if !selectable && !available {
+			app.clearSelection()
 		} else if available {
-			app.Board.SetClicked(true)
-			app.Board.SetClickedAt(xLog, yLog)
-			xPrev, yPrev := app.Board.GetClickedAtPrevious()
-			p, err = app.Board.GetPieceAt(xPrev, yPrev)
-			if err != board.ErrNoPieceAtPos {
-				app.Board.Move(p, xLog, yLog)
-				app.Board.ResetMovements()
+			xFrom, yFrom := app.selected.GetLogicalPosition()
+			m := board.Move{
+				From: yFrom*globals.TableDim + xFrom,
+				To:   yLog*globals.TableDim + xLog,
+			}
+			if app.Board.IsPromotion(m) {
+				// the move is done once the player chooses the piece
+				app.promoting = &m
+			} else {
+				app.move(m)
 			}
 		} else {
-			app.Board.SetClicked(true)
-			app.Board.SetClickedAt(xLog, yLog)
-			app.Board.SetAvailableMovements(p)
+			app.selected = p
+			app.available = p.GetAvailableMovements(app.Board)
 		}
 	}
 	return nil
 }
 
+// Makes the move m and checks if the game has ended
+func (app *App) move(m board.Move) {
+	if err := app.Board.Move(m); err != nil {
+		log.Println(err.Error())
+	}
+	app.clearSelection()
+	// check if the opponent can still play
+	app.Result = app.Board.GetResult()
+	app.savePGN()
+}
+
+// Returns true if the computer has to make the next move
+func (app *App) isComputerTurn() bool {
+	return app.computer != nil && *app.computer == app.Board.GetSideToMove()
+}
+
+// Starts searching the move of the computer in another goroutine. The search
+// uses a copy of the board, so the board can be painted in the meantime
+func (app *App) think() {
+	b := app.Board.Copy()
+	app.thinking = make(chan search.Result, 1)
+	go func(thinking chan<- search.Result) {
+		r, err := search.Search(b, search.Limits{MoveTime: app.moveTime, Table: app.table})
+		if err != nil {
+			log.Println(err.Error())
+		}
+		thinking <- r
+	}(app.thinking)
+}
+
+// Undoes or redoes a move with the function f, forgetting the piece selected
+func (app *App) undo(f func() error) {
+	if err := f(); err != nil {
+		log.Println(err.Error())
+		return
+	}
+	// against the computer, the move of the computer is undone or redone along
+	// with the move of the player, otherwise it would just play again
+	if app.isComputerTurn() {
+		f()
+	}
+	app.promoting = nil
+	app.clearSelection()
+	// undoing the last move of a finished game resumes it
+	app.Result = app.Board.GetResult()
+	app.savePGN()
+}
+
+// Saves the game played so far to the PGN file, if any
+func (app *App) savePGN() {
+	if app.pgnPath == "" {
+		return
+	}
+	f, err := os.Create(app.pgnPath)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	defer f.Close()
+	if err := app.Board.WritePGN(f, board.NewPGNTags()); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// Promotes the pawn waiting to be promoted to the piece of the promotion picker
+// at the cell x,y. Clicking anywhere else cancels the move
+func (app *App) choosePromotion(x, y int) {
+	c, column := app.Board.GetSideToMove(), app.promoting.To%globals.TableDim
+	pt, chosen := render.GetPromotionPickerChoice(c, column, x, y)
+	if chosen {
+		app.promoting.Promotion = pt
+		app.move(*app.promoting)
+	}
+	app.promoting = nil
+	app.clearSelection()
+}
+
+// Returns true if the selected piece can move to the position pos
+func (app *App) isAvailable(pos int) bool {
+	for _, npos := range app.available {
+		if npos == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// Forgets the piece selected by the player
+func (app *App) clearSelection() {
+	app.selected = nil
+	app.available = nil
+}
+
 // Draw draws the game screen.
 // Draw is called every frame (typically 1/60[s] for 60Hz display).
 func (app *App) Draw(screen *ebiten.Image) {
 	// Write your game's rendering.
-	app.Board.Paint(screen)
-	// update board with last frame value
-	app.Board.UpdateState()
+	render.Paint(screen, app.Board, app.available)
+	// Let the player choose the piece a pawn is promoted to
+	if app.promoting != nil {
+		render.PaintPromotionPicker(screen, app.Board.GetSideToMove(), app.promoting.To%globals.TableDim)
+	}
+	// Show the game being replayed, or the result once the game has ended
+	switch {
+	case app.replay != nil:
+		printMessage(screen, app.replay.GetStatus())
+	case app.Result == board.Checkmate:
+		printMessage(screen, fmt.Sprintf("Checkmate! %s wins", app.Board.GetSideToMove().Opponent()))
+	case app.Result == board.Stalemate:
+		printMessage(screen, "Stalemate! The game is a draw")
+	case app.thinking != nil:
+		printMessage(screen, "The computer is thinking...")
+	}
+}
+
+// Prints a message in the top of the screen, over a dark background so it can be
+// read on top of any cell
+func printMessage(screen *ebiten.Image, msg string) {
+	ebitenutil.DrawRect(screen, 0, 0, float64(globals.WindowWidth), 16, color.Black)
+	ebitenutil.DebugPrint(screen, msg)
 }
 
 // Layout takes the outside size (e.g., the window size) and returns the (logical) screen size.
@@ -63,7 +250,7 @@ func (app *App) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeig
 	return globals.WindowWidth, globals.WindowHeight
 }
 
-func (app *App) initApp() {
+func (app *App) initApp(fen, pgnPath string) {
 
 	// Window size and title
 	ebiten.SetWindowSize(globals.WindowWidth, globals.WindowHeight)
@@ -74,16 +261,85 @@ func (app *App) initApp() {
 
 	// Initializes app struct and prepares everything just to be painted
 	app.Board = &board.Board{}
-	app.Board.InitBoard()
-	app.Board.LoadImages()
+	if err := app.Board.LoadFEN(fen); err != nil {
+		log.Fatalln(err.Error())
+	}
+	render.LoadImages()
+	// The given position could be a finished game
+	app.Result = app.Board.GetResult()
+	app.pgnPath = pgnPath
+
+}
+
+// Makes the computer play against the player, who moves the pieces of color c.
+// The computer thinks each move for moveTime, with a transposition table of
+// hashSize megabytes
+func (app *App) initComputer(c board.Color, moveTime time.Duration, hashSize int) {
+	computer := c.Opponent()
+	app.computer = &computer
+	app.moveTime = moveTime
+	app.table = transposition.New(hashSize)
+}
 
+// Prepares the app to replay a game instead of playing it
+func (app *App) initReplay(replay *Replay) {
+	app.replay = replay
+	if err := app.Board.LoadFEN(replay.GetPosition()); err != nil {
+		log.Fatalln(err.Error())
+	}
 }
 
 func main() {
 
+	fen := flag.String("fen", board.StartFEN, "position to start the game from, in Forsyth-Edwards Notation")
+	savePGN := flag.String("save-pgn", "", "file the game is saved to, in Portable Game Notation, after every move")
+	pgn := flag.String("pgn", "", "PGN file with a game to replay instead of playing")
+	game := flag.Int("game", 1, "number of the game of the PGN file to replay, starting at 1")
+	perft := flag.Int("perft", 0, "count the leaf nodes of the tree of moves this deep from the -fen position, instead of playing")
+	divide := flag.Bool("divide", false, "with -perft, print the count below each move of the position")
+	play := flag.String("play", "", "color played against the computer, white or black. If not set, two players play on the same board")
+	moveTime := flag.Duration("movetime", time.Second, "with -play, time the computer thinks each move")
+	hash := flag.Int("hash", transposition.DefaultSize, "with -play, size of the transposition table of the computer, in megabytes")
+	flag.Parse()
+
+	if *perft > 0 {
+		if err := runPerft(*fen, *perft, *divide); err != nil {
+			log.Fatalln(err.Error())
+		}
+		return
+	}
+
 	app := &App{}
-	app.initApp()
+	app.initApp(*fen, *savePGN)
+	if *play != "" {
+		c, err := parseColor(*play)
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		if *hash < 1 || *hash > transposition.MaxSize {
+			log.Fatalf("invalid -hash %d, it has to be between 1 and %d\n", *hash, transposition.MaxSize)
+		}
+		app.initComputer(c, *moveTime, *hash)
+	}
+	if *pgn != "" {
+		replay, err := NewReplay(*pgn, *game)
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		app.initReplay(replay)
+	}
 	if err := ebiten.RunGame(app); err != nil {
 		log.Fatalln(err.Error())
 	}
 }
+
+// Returns the color named s, which is either white or black
+func parseColor(s string) (board.Color, error) {
+	switch s {
+	case board.White.String():
+		return board.White, nil
+	case board.Black.String():
+		return board.Black, nil
+	}
+	return board.White, fmt.Errorf("unknown color %q, it has to be white or black", s)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"ChessEngine/board"
+)
+
+// Counts the leaf nodes of the tree of legal moves depth moves deep from the
+// position fen and prints them. If divide is true, the count below each legal
+// move of the position is printed too
+func runPerft(fen string, depth int, divide bool) error {
+	b := &board.Board{}
+	if err := b.LoadFEN(fen); err != nil {
+		return err
+	}
+	start := time.Now()
+	nodes := 0
+	if divide {
+		for _, d := range b.PerftDivide(depth) {
+			fmt.Printf("%s: %d\n", d.Move, d.Nodes)
+			nodes += d.Nodes
+		}
+		fmt.Println()
+	} else {
+		nodes = b.Perft(depth)
+	}
+	elapsed := time.Since(start)
+	fmt.Printf("Nodes searched: %d\n", nodes)
+	fmt.Printf("Time: %v (%.0f nodes/s)\n", elapsed, float64(nodes)/elapsed.Seconds())
+	return nil
+}
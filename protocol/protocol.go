@@ -0,0 +1,58 @@
+// Package protocol has what the front ends that let chess GUIs play with the
+// engine have in common, whatever protocol they speak
+package protocol
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"ChessEngine/board"
+	"ChessEngine/search"
+)
+
+const (
+	EngineName   = "ChessEngine"
+	EngineAuthor = "Eloy Tolosa"
+)
+
+// A Writer writes the lines of a protocol. It can be used from several
+// goroutines, because the search writes its progress and its result while the
+// commands of the GUI are answered
+type Writer struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{out: w}
+}
+
+// Writes a line of the protocol
+func (w *Writer) Send(format string, a ...interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.out, format+"\n", a...)
+}
+
+// Starts searching the board with the given limits in another goroutine, and
+// calls done with the result once the search ends. The search uses its own
+// copy of the board, so the GUI can change the position while it goes on
+func StartSearch(b *board.Board, limits search.Limits, done func(search.Result)) {
+	go func(b *board.Board) {
+		// without legal moves the best move is board.NoMove
+		r, _ := search.Search(b, limits)
+		done(r)
+	}(b.Copy())
+}
+
+// Returns the moves in UCI notation separated by spaces, as both protocols
+// write the principal variation
+func FormatPV(moves []board.Move) string {
+	pv := make([]string, len(moves))
+	for i, m := range moves {
+		pv[i] = m.String()
+	}
+	return strings.Join(pv, " ")
+}
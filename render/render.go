@@ -0,0 +1,160 @@
+// Package render paints a board.Board in an ebiten window. The board package
+// only knows the rules of the game, so it can be used without a display
+package render
+
+import (
+	"fmt"
+	"image/color"
+	_ "image/png"
+	"log"
+	"os"
+
+	"ChessEngine/board"
+	"ChessEngine/globals"
+	"ChessEngine/utils"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+var images map[board.PieceType]*ebiten.Image
+
+func LoadImages() {
+
+	currDir, err := os.Getwd()
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	images = make(map[board.PieceType]*ebiten.Image)
+
+	// Append textures so we don't have to search them after this
+	images[board.WhitePawn] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_plt60.png", currDir, "assets/images"))
+	images[board.BlackPawn] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_pdt60.png", currDir, "assets/images"))
+	images[board.WhiteBishop] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_blt60.png", currDir, "assets/images"))
+	images[board.BlackBishop] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_bdt60.png", currDir, "assets/images"))
+	images[board.WhiteKnight] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_nlt60.png", currDir, "assets/images"))
+	images[board.BlackKnight] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_ndt60.png", currDir, "assets/images"))
+	images[board.WhiteRook] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_rlt60.png", currDir, "assets/images"))
+	images[board.BlackRook] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_rdt60.png", currDir, "assets/images"))
+	images[board.WhiteKing] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_klt60.png", currDir, "assets/images"))
+	images[board.BlackKing] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_kdt60.png", currDir, "assets/images"))
+	images[board.WhiteQueen] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_qlt60.png", currDir, "assets/images"))
+	images[board.BlackQueen] = utils.NewImage(fmt.Sprintf("%s/%s/Chess_qdt60.png", currDir, "assets/images"))
+
+}
+
+// Paints the cells and the pieces of the board, and marks the cells available
+// to the piece selected by the player
+func Paint(screen *ebiten.Image, b *board.Board, available []int) {
+	paintCells(screen)
+	paintPieces(screen, b)
+	paintAvailableMovements(screen, available)
+}
+
+func paintCells(screen *ebiten.Image) {
+	for i := 0; i < globals.TableDim; i++ {
+		for j := 0; j < globals.TableDim; j++ {
+			if (i+j)%2 == 0 {
+				ebitenutil.DrawRect(screen, float64(i*globals.CWidth), float64(j*globals.CHeight), float64(globals.CWidth), float64(globals.CHeight), color.White)
+			} else {
+				ebitenutil.DrawRect(screen, float64(i*globals.CWidth), float64(j*globals.CHeight), float64(globals.CWidth), float64(globals.CHeight), color.Black)
+			}
+		}
+	}
+}
+
+func paintPieces(screen *ebiten.Image, b *board.Board) {
+	for _, p := range b.GetPieces() {
+		// get x and y coordinates
+		logX, logY := p.GetLogicalPosition()
+		x, y := utils.GetAbsolutePosition(logX, logY)
+		// Center the images
+		x += (float64(globals.CWidth) - 60) / 2
+		y += (float64(globals.CHeight) - 60) / 2
+		// Apply transformations
+		geom := &ebiten.GeoM{}
+		geom.Translate(x, y)
+		// TODO read images in SVG format and scale them
+		screen.DrawImage(
+			images[p.GetPieceType()],
+			&ebiten.DrawImageOptions{
+				GeoM: *geom,
+			},
+		)
+	}
+}
+
+// Paints the pieces a pawn of color c can be promoted to, one per cell, in the
+// column x starting from the row the pawn is promoted at
+func PaintPromotionPicker(screen *ebiten.Image, c board.Color, x int) {
+	for i, pt := range board.GetPromotionPieces(c) {
+		absX, absY := utils.GetAbsolutePosition(x, promotionPickerRow(c, i))
+		ebitenutil.DrawRect(screen, absX, absY, float64(globals.CWidth), float64(globals.CHeight), color.Gray{Y: 128})
+		// Center the images
+		absX += (float64(globals.CWidth) - 60) / 2
+		absY += (float64(globals.CHeight) - 60) / 2
+		geom := &ebiten.GeoM{}
+		geom.Translate(absX, absY)
+		screen.DrawImage(images[pt], &ebiten.DrawImageOptions{
+			GeoM: *geom,
+		})
+	}
+}
+
+// Returns the piece of the promotion picker painted for color c in the column x
+// that is at the cell clickX,clickY, and false if there is none
+func GetPromotionPickerChoice(c board.Color, x, clickX, clickY int) (board.PieceType, bool) {
+	if clickX != x {
+		return board.NoPromotion, false
+	}
+	for i, pt := range board.GetPromotionPieces(c) {
+		if promotionPickerRow(c, i) == clickY {
+			return pt, true
+		}
+	}
+	return board.NoPromotion, false
+}
+
+// Returns the row in which the i-th piece of the promotion picker of color c
+// is painted. The picker starts at the row the pawns are promoted at
+func promotionPickerRow(c board.Color, i int) int {
+	if c == board.White {
+		return i
+	}
+	return globals.TableDim - 1 - i
+}
+
+func paintAvailableMovements(screen *ebiten.Image, available []int) {
+	if len(available) == 0 {
+		return
+	}
+	// get image
+	currDir, err := os.Getwd()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	imgPath := fmt.Sprintf("%s/%s/%s", currDir, "assets/images", "movement.png")
+	movementImage := utils.NewImage(imgPath)
+	// draw red circles at the given positions
+	for _, p := range available {
+		// get x and y coordinates
+		xLogic := int(p % globals.TableDim)
+		yLogic := int(p / globals.TableDim)
+		// center the dots
+		x := float64(xLogic * globals.CWidth)
+		y := float64(yLogic * globals.CHeight)
+		// declare geom struct
+		geom := &ebiten.GeoM{}
+		// scale (first)
+		xf := float64(globals.CWidth) / float64(movementImage.Bounds().Dx())
+		yf := float64(globals.CHeight) / float64(movementImage.Bounds().Dy())
+		geom.Scale(xf, yf)
+		// translate (then translate)
+		geom.Translate(x, y)
+		// draw image
+		screen.DrawImage(movementImage, &ebiten.DrawImageOptions{
+			GeoM: *geom,
+		})
+	}
+}
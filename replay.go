@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"ChessEngine/board"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// A Replay lets the user step through the moves of a game read from a PGN file
+type Replay struct {
+	// name of the game, shown on top of the board
+	title string
+	// positions of the game in FEN, from the initial one to the last one
+	positions []string
+	// index of the position shown in the board
+	current int
+}
+
+// Reads the n-th game (starting at 1) of the PGN file at path
+func NewReplay(path string, n int) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	games, err := board.ParsePGN(f)
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 || n > len(games) {
+		return nil, fmt.Errorf("game %d not found, %s has %d games", n, path, len(games))
+	}
+	game := games[n-1]
+	positions, err := game.GetPositions()
+	if err != nil {
+		return nil, err
+	}
+	return &Replay{
+		title:     fmt.Sprintf("%s - %s (%s)", game.Tags["White"], game.Tags["Black"], game.Result),
+		positions: positions,
+	}, nil
+}
+
+// Moves through the game with the arrow keys: right and left go one move
+// forward and backward, up and down go to the start and to the end of the game.
+// Returns true if the position shown has changed
+func (r *Replay) Update() bool {
+	previous := r.current
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		r.current++
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		r.current--
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		r.current = 0
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		r.current = len(r.positions) - 1
+	}
+	if r.current < 0 {
+		r.current = 0
+	}
+	if r.current >= len(r.positions) {
+		r.current = len(r.positions) - 1
+	}
+	return r.current != previous
+}
+
+// Returns the position shown in the board, in FEN
+func (r *Replay) GetPosition() string {
+	return r.positions[r.current]
+}
+
+// Returns the text shown on top of the board
+func (r *Replay) GetStatus() string {
+	return fmt.Sprintf("%s  move %d/%d  (arrow keys to navigate)", r.title, r.current, len(r.positions)-1)
+}
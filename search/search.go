@@ -0,0 +1,373 @@
+// Package search finds the best move of a board.Board position
+package search
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"ChessEngine/board"
+	"ChessEngine/evaluation"
+	"ChessEngine/globals"
+	"ChessEngine/transposition"
+)
+
+const (
+	// score of a checkmate. A mate found n plies away from the root scores
+	// MateScore-n, so shorter mates are preferred
+	MateScore = transposition.MateScore
+	// score greater than any other one
+	Infinity = MateScore + 1
+	// max number of plies a search goes deep
+	MaxDepth = transposition.MaxPly
+	// number of nodes searched between two checks of the time limit
+	checkInterval = 2048
+)
+
+var ErrNoLegalMoves = errors.New("there are no legal moves to search")
+
+// Limits tells when a search has to stop. A zero value means there is no
+// such limit
+type Limits struct {
+	// max number of plies to search. Without it, the search goes MaxDepth plies
+	// deep
+	Depth int
+	// max time to spend searching
+	MoveTime time.Duration
+	// the search stops as soon as this channel is closed
+	Stop <-chan struct{}
+	// if not nil, it is called with the result of every iteration completed,
+	// so the progress of the search can be shown while it goes on
+	Progress func(Result)
+	// if not nil, the results of searching each position are saved in this
+	// table, and reused when the position is found again, in this search or
+	// in the next ones
+	Table *transposition.Table
+}
+
+// A Result is the outcome of a search
+type Result struct {
+	// best move found
+	Move board.Move
+	// score of the best move, in centipawns, from the point of view of the
+	// player to move
+	Score int
+	// depth of the last iteration completed
+	Depth int
+	// number of positions searched
+	Nodes int
+	// time spent searching
+	Time time.Duration
+	// principal variation: the best move and the best replies expected after it
+	PV []board.Move
+}
+
+type searcher struct {
+	board *board.Board
+	table *transposition.Table
+	// time at which the search has to stop, zero if there is no time limit
+	deadline time.Time
+	stop     <-chan struct{}
+	start    time.Time
+	nodes    int
+	// best line found from each ply of the branch being searched
+	pv [MaxDepth + 1][]board.Move
+	// the search ran out of time, so the iteration being searched is discarded
+	stopped bool
+}
+
+// Returns the best move for the player to move in the position of the board,
+// searching the tree of moves with negamax alpha-beta and iterative deepening
+// until any of the limits is reached. The board is left as it was.
+// Returns ErrNoLegalMoves if the game has ended
+func Search(b *board.Board, limits Limits) (Result, error) {
+	moves := b.GetLegalMoves()
+	if len(moves) == 0 {
+		return Result{Move: board.NoMove}, ErrNoLegalMoves
+	}
+	s := &searcher{board: b, table: limits.Table, stop: limits.Stop, start: time.Now()}
+	if s.table != nil {
+		s.table.NewSearch()
+	}
+	if limits.MoveTime > 0 {
+		s.deadline = s.start.Add(limits.MoveTime)
+	}
+	maxDepth := limits.Depth
+	if maxDepth <= 0 || maxDepth > MaxDepth {
+		maxDepth = MaxDepth
+	}
+	orderMoves(b, moves)
+	// any legal move is better than nothing if time runs out before the first
+	// iteration is completed
+	result := Result{Move: moves[0]}
+	for depth := 1; depth <= maxDepth; depth++ {
+		m, score := s.searchRoot(moves, depth)
+		if s.stopped {
+			break
+		}
+		result = Result{
+			Move:  m,
+			Score: score,
+			Depth: depth,
+			Nodes: s.nodes,
+			Time:  time.Since(s.start),
+			PV:    append([]board.Move{}, s.pv[0]...),
+		}
+		if limits.Progress != nil {
+			limits.Progress(result)
+		}
+		// the best move is searched first in the next iteration
+		moveToFront(moves, m)
+		// searching deeper does not change a forced mate
+		if isMateScore(score) {
+			break
+		}
+	}
+	result.Nodes = s.nodes
+	result.Time = time.Since(s.start)
+	if len(result.PV) == 0 {
+		result.PV = []board.Move{result.Move}
+	}
+	return result, nil
+}
+
+// Returns the best of the moves of the root position and its score, searching
+// depth plies deep
+func (s *searcher) searchRoot(moves []board.Move, depth int) (best board.Move, alpha int) {
+	alpha = -Infinity
+	for _, m := range moves {
+		s.board.MakeMove(m)
+		score := -s.negamax(depth-1, 1, -Infinity, -alpha)
+		s.board.UnmakeMove()
+		if s.stopped {
+			return
+		}
+		if score > alpha {
+			alpha, best = score, m
+			s.updatePV(0, m)
+		}
+	}
+	s.store(0, depth, alpha, transposition.Exact, best)
+	return
+}
+
+// Returns the score of the position, from the point of view of the player to
+// move, searching depth plies deep. ply is the distance to the root. Scores
+// outside alpha,beta are cut to the closest bound
+func (s *searcher) negamax(depth, ply, alpha, beta int) int {
+	s.pv[ply] = s.pv[ply][:0]
+	if s.shouldStop() {
+		return 0
+	}
+	// the position may have been searched already, at least as deep
+	var tableMove board.Move
+	if s.table != nil {
+		if e, found := s.table.Probe(s.board.GetHash(), ply); found {
+			tableMove = e.Move
+			if e.Depth >= depth {
+				switch {
+				case e.Bound == transposition.Exact:
+					// the line below the position is not searched again, so
+					// it is taken from the table
+					s.tablePV(ply, depth)
+					return e.Score
+				case e.Bound == transposition.LowerBound && e.Score >= beta:
+					return beta
+				case e.Bound == transposition.UpperBound && e.Score <= alpha:
+					return alpha
+				}
+			}
+		}
+	}
+	moves := s.board.GetLegalMoves()
+	if len(moves) == 0 {
+		if s.board.IsInCheck(s.board.GetSideToMove()) {
+			return -MateScore + ply
+		}
+		return 0
+	}
+	// fifty move rule
+	if s.board.GetHalfmoveClock() >= 100 {
+		return 0
+	}
+	if depth == 0 {
+		return s.quiescence(alpha, beta)
+	}
+	s.nodes++
+	orderMoves(s.board, moves)
+	// the best move of the previous search of the position is tried first
+	moveToFront(moves, tableMove)
+	bound, best := transposition.UpperBound, board.NoMove
+	for _, m := range moves {
+		s.board.MakeMove(m)
+		score := -s.negamax(depth-1, ply+1, -beta, -alpha)
+		s.board.UnmakeMove()
+		if s.stopped {
+			return 0
+		}
+		if score >= beta {
+			s.store(ply, depth, beta, transposition.LowerBound, m)
+			return beta
+		}
+		if score > alpha {
+			alpha, bound, best = score, transposition.Exact, m
+			s.updatePV(ply, m)
+		}
+	}
+	s.store(ply, depth, alpha, bound, best)
+	return alpha
+}
+
+// Saves the result of searching the position in the transposition table, if any
+func (s *searcher) store(ply, depth, score int, bound transposition.Bound, m board.Move) {
+	if s.table != nil {
+		s.table.Store(s.board.GetHash(), ply, depth, score, bound, m)
+	}
+}
+
+// Saves as the best line from ply the best moves saved in the table, starting
+// from the position of the board, up to depth moves. The line ends early if a
+// position is not in the table, or its move is not legal because another
+// position with the same key overwrote it
+func (s *searcher) tablePV(ply, depth int) {
+	made := 0
+	for ; made < depth; made++ {
+		e, found := s.table.Probe(s.board.GetHash(), ply+made)
+		if !found || !isLegal(s.board, e.Move) {
+			break
+		}
+		s.pv[ply] = append(s.pv[ply], e.Move)
+		s.board.MakeMove(e.Move)
+	}
+	for ; made > 0; made-- {
+		s.board.UnmakeMove()
+	}
+}
+
+// Returns true if m is one of the legal moves of the board
+func isLegal(b *board.Board, m board.Move) bool {
+	for _, legal := range b.GetLegalMoves() {
+		if legal == m {
+			return true
+		}
+	}
+	return false
+}
+
+// Saves the move m, followed by the best line found after it, as the best line
+// from ply
+func (s *searcher) updatePV(ply int, m board.Move) {
+	s.pv[ply] = append(append(s.pv[ply][:0], m), s.pv[ply+1]...)
+}
+
+// Returns the score of the position once there are no more captures, so the
+// search does not stop in the middle of an exchange of pieces
+func (s *searcher) quiescence(alpha, beta int) int {
+	if s.shouldStop() {
+		return 0
+	}
+	s.nodes++
+	// the player to move does not have to capture, so the score is at least
+	// the score of the position as it is
+	standPat := evaluation.Evaluate(s.board)
+	if standPat >= beta {
+		return beta
+	}
+	if standPat > alpha {
+		alpha = standPat
+	}
+	var captures []board.Move
+	for _, m := range s.board.GetLegalMoves() {
+		if m.Is(board.FlagCapture) || m.Is(board.FlagPromotion) {
+			captures = append(captures, m)
+		}
+	}
+	orderMoves(s.board, captures)
+	for _, m := range captures {
+		s.board.MakeMove(m)
+		score := -s.quiescence(-beta, -alpha)
+		s.board.UnmakeMove()
+		if s.stopped {
+			return 0
+		}
+		if score >= beta {
+			return beta
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return alpha
+}
+
+// Returns true if the search has to stop because it ran out of time or it was
+// asked to stop
+func (s *searcher) shouldStop() bool {
+	if s.stopped || s.nodes%checkInterval != 0 {
+		return s.stopped
+	}
+	// asking for the time is slow, so it is not done at every node
+	if !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		s.stopped = true
+	}
+	select {
+	case <-s.stop:
+		s.stopped = true
+	default:
+	}
+	return s.stopped
+}
+
+// Sorts the moves so the most promising ones are searched first, which makes
+// alpha-beta cut more branches: captures of valuable pieces by cheap ones and
+// promotions go first
+func orderMoves(b *board.Board, moves []board.Move) {
+	priority := func(m board.Move) int {
+		score := 0
+		if m.Is(board.FlagPromotion) {
+			score += evaluation.MiddlegameValues[m.Promotion]
+		}
+		if m.Is(board.FlagCapture) {
+			attacker, _ := b.GetPieceAt(m.From%globals.TableDim, m.From/globals.TableDim)
+			// a pawn capturing en passant lands on an empty cell
+			victim := evaluation.MiddlegameValues[board.WhitePawn]
+			if captured, err := b.GetPieceAt(m.To%globals.TableDim, m.To/globals.TableDim); err == nil {
+				victim = evaluation.MiddlegameValues[captured.GetPieceType()]
+			}
+			score += 10*victim - evaluation.MiddlegameValues[attacker.GetPieceType()]
+		}
+		return score
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return priority(moves[i]) > priority(moves[j])
+	})
+}
+
+// Moves m to the first position of moves, keeping the order of the rest
+func moveToFront(moves []board.Move, m board.Move) {
+	for i := range moves {
+		if moves[i] == m {
+			copy(moves[1:i+1], moves[:i])
+			moves[0] = m
+			return
+		}
+	}
+}
+
+// Returns true if the score is a checkmate, for any of the players
+func isMateScore(score int) bool {
+	return score > MateScore-MaxDepth || score < -MateScore+MaxDepth
+}
+
+// Returns the number of moves until checkmate for a mate score, negative if the
+// player to move is the one mated, and false if the score is not a mate
+func MateIn(score int) (int, bool) {
+	switch {
+	case !isMateScore(score):
+		return 0, false
+	case score > 0:
+		return (MateScore - score + 1) / 2, true
+	default:
+		return -(MateScore + score) / 2, true
+	}
+}
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"ChessEngine/board"
+	"ChessEngine/transposition"
+)
+
+var searchTests = []struct {
+	name string
+	fen  string
+	// best move, in UCI notation
+	move string
+}{
+	{"mate in one", "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "a1a8"},
+	{"win the queen", "4k3/8/8/3q4/8/8/3R4/3K4 w - - 0 1", "d2d5"},
+	{"take the attacking rook", "4k3/8/8/3r4/8/8/3Q4/3K4 w - - 0 1", "d2d5"},
+	{"scholar's mate", "r1bqkbnr/pppp1ppp/2n5/4p3/2B1P3/5Q2/PPPP1PPP/RNB1K1NR w KQkq - 0 1", "f3f7"},
+}
+
+func TestSearch(t *testing.T) {
+	for _, tt := range searchTests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &board.Board{}
+			if err := b.LoadFEN(tt.fen); err != nil {
+				t.Fatal(err)
+			}
+			// with and without a transposition table, which is kept between
+			// searches
+			table := transposition.New(1)
+			for _, limits := range []Limits{{Depth: 4}, {Depth: 4, Table: table}, {Depth: 4, Table: table}} {
+				r, err := Search(b, limits)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.Move.String() != tt.move {
+					t.Errorf("got %s (score %d), want %s", r.Move, r.Score, tt.move)
+				}
+			}
+			// the board has to be left as it was
+			if fen := b.GetFEN(); fen != tt.fen {
+				t.Errorf("board changed to %s", fen)
+			}
+		})
+	}
+}
+
+func TestSearchTimeLimit(t *testing.T) {
+	b := &board.Board{}
+	if err := b.LoadFEN(board.StartFEN); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := Search(b, Limits{MoveTime: 200 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("search took %v", elapsed)
+	}
+}
+
+func TestSearchNoLegalMoves(t *testing.T) {
+	b := &board.Board{}
+	// fool's mate
+	if err := b.LoadFEN("rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Search(b, Limits{Depth: 1}); err != ErrNoLegalMoves {
+		t.Errorf("got error %v, want %v", err, ErrNoLegalMoves)
+	}
+}
+
+func TestSearchPV(t *testing.T) {
+	b := &board.Board{}
+	if err := b.LoadFEN(board.StartFEN); err != nil {
+		t.Fatal(err)
+	}
+	// the second search finds the positions of the first one in the table,
+	// which still have to give the whole line
+	table := transposition.New(1)
+	var r Result
+	for i := 0; i < 2; i++ {
+		var err error
+		if r, err = Search(b, Limits{Depth: 5, Table: table}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(r.PV) != r.Depth {
+		t.Fatalf("got PV %v of %d moves, want %d", r.PV, len(r.PV), r.Depth)
+	}
+	if r.PV[0] != r.Move {
+		t.Errorf("got PV starting with %s, want %s", r.PV[0], r.Move)
+	}
+	for _, m := range r.PV {
+		if err := b.MoveUCI(m.String()); err != nil {
+			t.Fatalf("PV %v: %s: %v", r.PV, m, err)
+		}
+	}
+}
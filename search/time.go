@@ -0,0 +1,31 @@
+package search
+
+import "time"
+
+const (
+	// number of moves a player is expected to make with the time left, when the
+	// time control does not tell it
+	defaultMovesToGo = 30
+	// time kept back for the delays of the communication with the GUI
+	timeMargin = 50 * time.Millisecond
+)
+
+// Returns the time to think the next move for a player that has remaining time
+// left on the clock, gains increment after every move and has to make movesToGo
+// moves before the next time control (0 if the game has to be completed with
+// the time left)
+func AllocateTime(remaining, increment time.Duration, movesToGo int) time.Duration {
+	if movesToGo <= 0 {
+		movesToGo = defaultMovesToGo
+	}
+	t := remaining/time.Duration(movesToGo) + increment/2
+	// never use the whole clock
+	if max := remaining - timeMargin; t > max {
+		t = max
+	}
+	// thinking a little is always better than losing on time
+	if t < time.Millisecond {
+		t = time.Millisecond
+	}
+	return t
+}
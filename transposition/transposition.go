@@ -0,0 +1,199 @@
+// Package transposition caches the results of searching board.Board positions,
+// so a search reaching a position again, by another order of moves or in a later
+// search, can reuse them
+package transposition
+
+import (
+	"unsafe"
+
+	"ChessEngine/board"
+)
+
+const (
+	// score of a checkmate in the root position. A mate found n plies away
+	// from the root scores MateScore-n
+	MateScore = 100000
+	// max number of plies from the root a search can reach
+	MaxPly = 64
+	// default and max size of the table, in megabytes
+	DefaultSize = 16
+	MaxSize     = 1024
+)
+
+// A Bound tells how the score of an entry relates to the real score of the
+// position, which depends on the alpha-beta window it was searched with
+type Bound uint8
+
+const (
+	// the entry is empty
+	NoBound Bound = iota
+	// the score is the real one
+	Exact
+	// the real score is at least the score, the search was cut by a move too
+	// good (fail high)
+	LowerBound
+	// the real score is at most the score, no move reached alpha (fail low)
+	UpperBound
+)
+
+// An Entry is what is known about a position
+type Entry struct {
+	// best move found, or the move that cut the search. It is board.NoMove if
+	// there is none
+	Move board.Move
+	// score of the position from the point of view of the player to move
+	Score int
+	// number of plies the position was searched
+	Depth int
+	Bound Bound
+}
+
+// entries take 16 bytes, to fit as many as possible in the table. The slot of
+// an entry already gives the lowest bits of the key, so only the highest 32
+// bits are stored, and the move is packed in 32 bits
+type entry struct {
+	key        uint32
+	move       packedMove
+	score      int32
+	depth      int16
+	bound      Bound
+	generation uint8
+}
+
+// A Table is a hash table of fixed size with the entries of the positions,
+// indexed by their Zobrist key. When two positions go to the same slot, the
+// entry searched deeper is kept, unless it comes from an older search
+type Table struct {
+	entries []entry
+	// the number of entries is a power of two, so the slot of a key is its
+	// lowest bits
+	mask uint64
+	// number of the search going on, entries of older searches are replaced
+	// first
+	generation uint8
+}
+
+// Returns a table that takes up to size megabytes. It has at least one entry
+func New(size int) *Table {
+	n := uint64(size) * 1024 * 1024 / uint64(unsafe.Sizeof(entry{}))
+	// round down to a power of two
+	slots := uint64(1)
+	for slots*2 <= n {
+		slots *= 2
+	}
+	return &Table{entries: make([]entry, slots), mask: slots - 1}
+}
+
+// Removes every entry, for instance when a new game starts
+func (t *Table) Clear() {
+	for i := range t.entries {
+		t.entries[i] = entry{}
+	}
+	t.generation = 0
+}
+
+// Tells the table a new search starts, so the entries of the previous ones are
+// replaced before the new ones
+func (t *Table) NewSearch() {
+	t.generation++
+}
+
+// Returns the entry of the position with the given key, found ply plies away
+// from the root of the search, and false if there is none
+func (t *Table) Probe(key uint64, ply int) (Entry, bool) {
+	e := &t.entries[key&t.mask]
+	if e.bound == NoBound || e.key != uint32(key>>32) {
+		return Entry{}, false
+	}
+	return Entry{
+		Move:  e.move.unpack(),
+		Score: fromTable(int(e.score), ply),
+		Depth: int(e.depth),
+		Bound: e.bound,
+	}, true
+}
+
+// Saves the result of searching the position with the given key, found ply
+// plies away from the root, depth plies deep. It may replace the entry of
+// another position, or be dropped if that entry is more valuable
+func (t *Table) Store(key uint64, ply, depth, score int, bound Bound, move board.Move) {
+	e := &t.entries[key&t.mask]
+	replace := e.bound == NoBound || e.key == uint32(key>>32) ||
+		e.generation != t.generation || depth >= int(e.depth)
+	if !replace {
+		return
+	}
+	// a search that did not find a best move keeps the one of the previous
+	// search of the position
+	packed := pack(move)
+	if move == board.NoMove && e.key == uint32(key>>32) {
+		packed = e.move
+	}
+	*e = entry{
+		key:        uint32(key >> 32),
+		move:       packed,
+		score:      int32(toTable(score, ply)),
+		depth:      int16(depth),
+		bound:      bound,
+		generation: t.generation,
+	}
+}
+
+// A packedMove is a board.Move in 32 bits: the cells it goes from and to take
+// 6 bits each, the promotion piece 4 bits and the flags the highest 8 bits
+type packedMove uint32
+
+func pack(m board.Move) packedMove {
+	return packedMove(m.From) | packedMove(m.To)<<6 | packedMove(m.Promotion)<<12 | packedMove(m.Flags)<<16
+}
+
+func (p packedMove) unpack() board.Move {
+	return board.Move{
+		From:      int(p & 63),
+		To:        int(p >> 6 & 63),
+		Promotion: board.PieceType(p >> 12 & 15),
+		Flags:     board.MoveFlags(p >> 16),
+	}
+}
+
+// Returns the part of the table being used, in permill, as the UCI protocol
+// expects it
+func (t *Table) GetUsage() int {
+	// looking at the first entries is enough, keys are spread evenly
+	n := len(t.entries)
+	if n > 1000 {
+		n = 1000
+	}
+	used := 0
+	for _, e := range t.entries[:n] {
+		if e.bound != NoBound && e.generation == t.generation {
+			used++
+		}
+	}
+	return used * 1000 / n
+}
+
+// Mate scores count the plies from the root of the search, which is different
+// for every search reaching the position. The table stores them counting the
+// plies from the position itself
+func toTable(score, ply int) int {
+	switch {
+	case score > MateScore-MaxPly:
+		return score + ply
+	case score < -MateScore+MaxPly:
+		return score - ply
+	}
+	return score
+}
+
+// Returns the score stored in the table as a score of a position ply plies away
+// from the root of the search
+func fromTable(score, ply int) int {
+	switch {
+	case score > MateScore-MaxPly:
+		return score - ply
+	case score < -MateScore+MaxPly:
+		return score + ply
+	}
+	return score
+}
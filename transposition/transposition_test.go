@@ -0,0 +1,80 @@
+package transposition
+
+import (
+	"testing"
+	"unsafe"
+
+	"ChessEngine/board"
+)
+
+func TestStoreProbe(t *testing.T) {
+	table := New(1)
+	// a pawn capturing and promoting, so every field of the move is stored
+	m := board.Move{From: 12, To: 3, Promotion: board.WhiteKnight, Flags: board.FlagCapture | board.FlagPromotion}
+	table.Store(0x1234, 2, 5, 30, LowerBound, m)
+	e, found := table.Probe(0x1234, 2)
+	if !found {
+		t.Fatal("entry not found")
+	}
+	if want := (Entry{Move: m, Score: 30, Depth: 5, Bound: LowerBound}); e != want {
+		t.Errorf("got %+v, want %+v", e, want)
+	}
+	// another key going to the same slot
+	if _, found := table.Probe(0x1234+1<<32, 2); found {
+		t.Error("found the entry of another position")
+	}
+}
+
+func TestMateScores(t *testing.T) {
+	table := New(1)
+	// mate in 3 plies from a position 2 plies away from the root
+	table.Store(1, 2, 4, MateScore-5, Exact, board.NoMove)
+	// the same position 4 plies away from the root of another search is still
+	// a mate in 3 plies
+	if e, _ := table.Probe(1, 4); e.Score != MateScore-7 {
+		t.Errorf("got score %d, want %d", e.Score, MateScore-7)
+	}
+	table.Store(2, 1, 4, -MateScore+3, Exact, board.NoMove)
+	if e, _ := table.Probe(2, 0); e.Score != -MateScore+2 {
+		t.Errorf("got score %d, want %d", e.Score, -MateScore+2)
+	}
+}
+
+func TestReplacement(t *testing.T) {
+	table := New(1)
+	// keys going to the same slot
+	deep, shallow := uint64(1), uint64(1)+1<<32
+	table.Store(deep, 0, 8, 10, Exact, board.NoMove)
+	// a shallower search of another position does not replace a deeper one
+	table.Store(shallow, 0, 2, 20, Exact, board.NoMove)
+	if _, found := table.Probe(deep, 0); !found {
+		t.Error("deeper entry replaced in the same search")
+	}
+	// unless the deeper one is from an older search
+	table.NewSearch()
+	table.Store(shallow, 0, 2, 20, Exact, board.NoMove)
+	if _, found := table.Probe(shallow, 0); !found {
+		t.Error("older entry not replaced")
+	}
+	// a search without a best move keeps the previous one
+	m := board.Move{From: 1, To: 2}
+	table.Store(3, 0, 1, 0, Exact, m)
+	table.Store(3, 0, 2, -5, UpperBound, board.NoMove)
+	if e, _ := table.Probe(3, 0); e.Move != m {
+		t.Errorf("got move %v, want %v", e.Move, m)
+	}
+	table.Clear()
+	if _, found := table.Probe(3, 0); found {
+		t.Error("entry found after clearing the table")
+	}
+}
+
+func TestNew(t *testing.T) {
+	if size := unsafe.Sizeof(entry{}); size != 16 {
+		t.Fatalf("got entries of %d bytes, want 16", size)
+	}
+	// the whole size asked for is used
+	if n := len(New(DefaultSize).entries); n != DefaultSize*1024*1024/16 {
+		t.Errorf("got %d entries, want %d", n, DefaultSize*1024*1024/16)
+	}
+}
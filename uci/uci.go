@@ -0,0 +1,233 @@
+// Package uci lets chess GUIs play with the engine through the Universal Chess
+// Interface protocol
+package uci
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"ChessEngine/board"
+	"ChessEngine/protocol"
+	"ChessEngine/search"
+	"ChessEngine/transposition"
+)
+
+// An engine keeps the state of the protocol: the position set by the GUI and
+// the search going on, if any
+type engine struct {
+	board *board.Board
+	// results of the previous searches of the game
+	table *transposition.Table
+	// output is written from the search goroutine too
+	out *protocol.Writer
+	// closing stop ends the search going on, which closes done once its best
+	// move is written. Both are nil if the engine is not searching
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Reads the commands of the protocol from r and writes the answers to w until
+// the quit command is received or r is closed. The engine starts with a
+// transposition table of hashSize megabytes, which the GUI can change with the
+// Hash option
+func Run(r io.Reader, w io.Writer, hashSize int) error {
+	e := &engine{board: &board.Board{}, table: transposition.New(hashSize), out: protocol.NewWriter(w)}
+	if err := e.board.LoadFEN(board.StartFEN); err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "uci":
+			e.out.Send("id name %s", protocol.EngineName)
+			e.out.Send("id author %s", protocol.EngineAuthor)
+			e.out.Send("option name Hash type spin default %d min 1 max %d", transposition.DefaultSize, transposition.MaxSize)
+			e.out.Send("uciok")
+		case "isready":
+			e.out.Send("readyok")
+		case "setoption":
+			e.stopSearch()
+			if err := e.setOption(fields[1:]); err != nil {
+				e.out.Send("info string %s", err.Error())
+			}
+		case "ucinewgame":
+			e.stopSearch()
+			e.board.LoadFEN(board.StartFEN)
+			e.table.Clear()
+		case "position":
+			e.stopSearch()
+			if err := e.setPosition(fields[1:]); err != nil {
+				e.out.Send("info string %s", err.Error())
+			}
+		case "go":
+			e.stopSearch()
+			e.startSearch(fields[1:])
+		case "stop":
+			e.stopSearch()
+		case "quit":
+			e.stopSearch()
+			return nil
+		}
+		// unknown commands are ignored, as the protocol says
+	}
+	e.stopSearch()
+	return scanner.Err()
+}
+
+// Sets the option of the arguments of the setoption command:
+//
+//	name <id> [value <x>]
+func (e *engine) setOption(args []string) error {
+	if len(args) != 4 || args[0] != "name" || args[2] != "value" {
+		return fmt.Errorf("invalid setoption command %q", strings.Join(args, " "))
+	}
+	switch args[1] {
+	case "Hash":
+		size, err := strconv.Atoi(args[3])
+		if err != nil || size < 1 || size > transposition.MaxSize {
+			return fmt.Errorf("invalid Hash value %q", args[3])
+		}
+		e.table = transposition.New(size)
+	default:
+		return fmt.Errorf("unknown option %q", args[1])
+	}
+	return nil
+}
+
+// Sets up the position of the arguments of the position command:
+//
+//	startpos [moves <move>...]
+//	fen <fen> [moves <move>...]
+//
+// If the FEN or any of the moves is not valid, the position is not modified
+func (e *engine) setPosition(args []string) error {
+	moves := len(args)
+	for i, arg := range args {
+		if arg == "moves" {
+			moves = i
+			break
+		}
+	}
+	var fen string
+	switch {
+	case len(args) > 0 && args[0] == "startpos":
+		fen = board.StartFEN
+	case len(args) > 0 && args[0] == "fen":
+		fen = strings.Join(args[1:moves], " ")
+	default:
+		return fmt.Errorf("invalid position command %q", strings.Join(args, " "))
+	}
+	// the moves are made in another board, which replaces the position once
+	// all of them are legal
+	b := &board.Board{}
+	if err := b.LoadFEN(fen); err != nil {
+		return err
+	}
+	if moves < len(args) {
+		for _, m := range args[moves+1:] {
+			if err := b.MoveUCI(m); err != nil {
+				return err
+			}
+		}
+	}
+	e.board = b
+	return nil
+}
+
+// Starts searching the position with the limits of the arguments of the go
+// command. The best move is written once the search ends
+func (e *engine) startSearch(args []string) {
+	limits, infinite := e.parseLimits(args)
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+	limits.Stop = e.stop
+	limits.Progress = e.sendInfo
+	limits.Table = e.table
+	stop, done := e.stop, e.done
+	protocol.StartSearch(e.board, limits, func(r search.Result) {
+		// an infinite search only ends when the GUI asks for it
+		if infinite {
+			<-stop
+		}
+		// without legal moves the best move is board.NoMove, written 0000
+		e.out.Send("bestmove %s", r.Move)
+		close(done)
+	})
+}
+
+// Stops the search going on, if any, and waits until its best move is written
+func (e *engine) stopSearch() {
+	if e.stop == nil {
+		return
+	}
+	close(e.stop)
+	<-e.done
+	e.stop, e.done = nil, nil
+}
+
+// Returns the limits of the search set by the arguments of the go command, and
+// true if the search has to go on until it is stopped
+func (e *engine) parseLimits(args []string) (limits search.Limits, infinite bool) {
+	var remaining, increment time.Duration
+	movesToGo := 0
+	for i := 0; i < len(args); i++ {
+		if args[i] == "infinite" {
+			infinite = true
+			continue
+		}
+		// the rest of the arguments have a number as value
+		if i+1 >= len(args) {
+			break
+		}
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil {
+			continue
+		}
+		ms := time.Duration(n) * time.Millisecond
+		switch args[i] {
+		case "depth":
+			limits.Depth = n
+		case "movetime":
+			limits.MoveTime = ms
+		case "movestogo":
+			movesToGo = n
+		case "wtime", "btime":
+			if (args[i] == "wtime") == (e.board.GetSideToMove() == board.White) {
+				remaining = ms
+			}
+		case "winc", "binc":
+			if (args[i] == "winc") == (e.board.GetSideToMove() == board.White) {
+				increment = ms
+			}
+		default:
+			continue
+		}
+		i++
+	}
+	if limits.MoveTime == 0 && remaining > 0 {
+		limits.MoveTime = search.AllocateTime(remaining, increment, movesToGo)
+	}
+	return
+}
+
+// Writes the result of an iteration of the search as an info line
+func (e *engine) sendInfo(r search.Result) {
+	score := fmt.Sprintf("cp %d", r.Score)
+	if moves, ok := search.MateIn(r.Score); ok {
+		score = fmt.Sprintf("mate %d", moves)
+	}
+	// the speed is not known until some time has passed
+	nps := ""
+	if r.Time > 0 {
+		nps = fmt.Sprintf(" nps %d", int64(float64(r.Nodes)/r.Time.Seconds()))
+	}
+	e.out.Send("info depth %d score %s nodes %d%s time %d hashfull %d pv %s", r.Depth, score, r.Nodes, nps, r.Time.Milliseconds(), e.table.GetUsage(), protocol.FormatPV(r.PV))
+}
@@ -0,0 +1,181 @@
+package uci
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A session runs the engine in another goroutine, sending it commands and
+// reading its answers as a GUI would
+type session struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan string
+	done  chan error
+}
+
+func newSession(t *testing.T) *session {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	s := &session{t: t, in: inW, lines: make(chan string, 1000), done: make(chan error, 1)}
+	go func() {
+		err := Run(inR, outW, 1)
+		outW.Close()
+		s.done <- err
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			s.lines <- scanner.Text()
+		}
+		close(s.lines)
+	}()
+	return s
+}
+
+// Sends a command to the engine
+func (s *session) send(cmd string) {
+	s.t.Helper()
+	if _, err := io.WriteString(s.in, cmd+"\n"); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// Reads the answers of the engine until one starts with prefix, and returns
+// it along with the lines read before it
+func (s *session) expect(prefix string) (line string, before []string) {
+	s.t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				s.t.Fatalf("engine stopped before writing %q, after %q", prefix, before)
+			}
+			if strings.HasPrefix(line, prefix) {
+				return line, before
+			}
+			before = append(before, line)
+		case <-timeout:
+			s.t.Fatalf("engine did not write %q, after %q", prefix, before)
+		}
+	}
+}
+
+// Quits the engine and checks it ends without errors
+func (s *session) quit() {
+	s.t.Helper()
+	s.send("quit")
+	if err := <-s.done; err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	s := newSession(t)
+	s.send("uci")
+	_, before := s.expect("uciok")
+	want := []string{"id name ChessEngine", "id author Eloy Tolosa", "option name Hash type spin default 16 min 1 max 1024"}
+	if strings.Join(before, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q before uciok, want %q", before, want)
+	}
+	s.send("isready")
+	s.expect("readyok")
+	s.quit()
+}
+
+func TestGo(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		bestmove string
+	}{
+		{"mate in one", []string{"position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "go depth 3"}, "bestmove a1a8"},
+		{"moves from the initial position", []string{"position startpos moves e2e4 e7e5 d1h5 b8c6 f1c4 g8f6", "go depth 2"}, "bestmove h5f7"},
+		{"moves from a FEN", []string{"position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1 moves a1b1 g8h8", "go depth 3"}, "bestmove b1b8"},
+		{"clock", []string{"position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "go wtime 2000 btime 2000 winc 100 binc 100"}, "bestmove a1a8"},
+		{"mated", []string{"position fen rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", "go depth 2"}, "bestmove 0000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSession(t)
+			for _, cmd := range tt.commands {
+				s.send(cmd)
+			}
+			if line, _ := s.expect("bestmove"); line != tt.bestmove {
+				t.Errorf("got %q, want %q", line, tt.bestmove)
+			}
+			s.quit()
+		})
+	}
+}
+
+func TestInfo(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos")
+	s.send("go depth 3")
+	_, info := s.expect("bestmove")
+	if len(info) != 3 {
+		t.Fatalf("got info lines %q, want one per depth", info)
+	}
+	for i, line := range info {
+		fields := strings.Fields(line)
+		if fields[0] != "info" || fields[1] != "depth" || fields[2] != string(rune('1'+i)) {
+			t.Errorf("got %q, want the info of depth %d", line, i+1)
+		}
+		// the principal variation has a move per ply
+		if pv := strings.Index(line, " pv "); pv < 0 || len(strings.Fields(line[pv+4:])) != i+1 {
+			t.Errorf("got %q, want a pv of %d moves", line, i+1)
+		}
+	}
+	s.quit()
+}
+
+func TestInfinite(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos")
+	s.send("go infinite")
+	// the search goes on, without a best move, until it is stopped
+	s.send("isready")
+	if _, before := s.expect("readyok"); strings.Contains(strings.Join(before, "\n"), "bestmove") {
+		t.Errorf("got a best move before stop: %q", before)
+	}
+	s.send("stop")
+	s.expect("bestmove")
+	s.quit()
+}
+
+func TestInvalidCommands(t *testing.T) {
+	s := newSession(t)
+	// the position stays the same when a command is not valid
+	s.send("position fen rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3")
+	s.send("position startpos moves e2e4 e1e3")
+	if line, _ := s.expect("info string"); !strings.Contains(line, "e1e3") {
+		t.Errorf("got %q, want the illegal move", line)
+	}
+	s.send("position fen 8/8/8/8/8/8/8/8 w - - 0 1")
+	s.expect("info string")
+	s.send("setoption name Hash value 0")
+	s.expect("info string")
+	s.send("setoption name Hash value 2")
+	s.send("unknown command")
+	s.send("go depth 1")
+	if line, before := s.expect("bestmove"); line != "bestmove 0000" || len(before) != 0 {
+		t.Errorf("got %q after %q, want bestmove 0000 from the position set before", line, before)
+	}
+	s.quit()
+}
+
+func TestEndOfInput(t *testing.T) {
+	s := newSession(t)
+	s.send("go infinite")
+	// closing the input stops the search
+	s.in.Close()
+	s.expect("bestmove")
+	if err := <-s.done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,333 @@
+// Package xboard lets XBoard and other hosts play with the engine through the
+// Chess Engine Communication Protocol
+package xboard
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"ChessEngine/board"
+	"ChessEngine/protocol"
+	"ChessEngine/search"
+	"ChessEngine/transposition"
+)
+
+const (
+	// time to think each move when the host sets no time control
+	defaultMoveTime = 5 * time.Second
+	// mate scores are written as 100000 plus the number of moves to mate
+	mateScore = 100000
+)
+
+// An engine keeps the state of the protocol: the game being played, the
+// color the engine plays, the time control and the search going on, if any
+type engine struct {
+	board *board.Board
+	// results of the previous searches of the game
+	table *transposition.Table
+	// output is written from the search goroutine too
+	out *protocol.Writer
+	// color played by the engine. In force mode the engine plays none, it just
+	// checks the moves of the host
+	color board.Color
+	force bool
+	// write the progress of the search, after the post command
+	post bool
+	// time control: max depth (sd), fixed time per move (st), conventional
+	// clock (level) and the time left on the clock of the engine (time)
+	depth           int
+	moveTime        time.Duration
+	movesPerSession int
+	increment       time.Duration
+	clock           time.Duration
+	// the engine is searching its move, which is received from results.
+	// Closing stop ends the search, and it is set to nil once closed
+	searching bool
+	stop      chan struct{}
+	results   chan search.Result
+}
+
+// Reads the commands of the protocol from r and writes the answers to w until
+// the quit command is received or r is closed. The engine starts with a
+// transposition table of hashSize megabytes, which the host can change with the
+// memory command
+func Run(r io.Reader, w io.Writer, hashSize int) error {
+	e := &engine{
+		board:   &board.Board{},
+		table:   transposition.New(hashSize),
+		out:     protocol.NewWriter(w),
+		results: make(chan search.Result, 1),
+	}
+	e.newGame()
+	// commands are read in their own goroutine, so the engine can wait for
+	// them and for the end of the search at the same time
+	lines := make(chan string)
+	var scanErr error
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		scanErr = scanner.Err()
+		close(lines)
+	}()
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				e.abortSearch()
+				return scanErr
+			}
+			if quit := e.handle(strings.Fields(line)); quit {
+				e.abortSearch()
+				return nil
+			}
+		case r := <-e.results:
+			e.searching, e.stop = false, nil
+			e.play(r.Move)
+		}
+	}
+}
+
+// Runs a command of the protocol. Returns true if the engine has to quit
+func (e *engine) handle(fields []string) bool {
+	if len(fields) == 0 {
+		return false
+	}
+	args := fields[1:]
+	switch fields[0] {
+	case "xboard", "accepted", "rejected", "random", "hard", "easy", "computer", "name", "otim", "draw":
+		// nothing to do, draw offers are declined by not answering them
+	case "protover":
+		e.out.Send("feature myname=\"%s\" usermove=1 setboard=1 ping=1 memory=1 sigint=0 sigterm=0 colors=0 analyze=0 draw=0 done=1", protocol.EngineName)
+	case "new":
+		e.abortSearch()
+		e.newGame()
+	case "setboard":
+		e.abortSearch()
+		if err := e.board.LoadFEN(strings.Join(args, " ")); err != nil {
+			e.out.Send("tellusererror Illegal position: %s", err.Error())
+		}
+	case "force":
+		e.abortSearch()
+		e.force = true
+	case "go":
+		e.abortSearch()
+		e.force = false
+		e.color = e.board.GetSideToMove()
+		e.think()
+	case "usermove":
+		e.abortSearch()
+		if len(args) == 0 || e.board.MoveUCI(args[0]) != nil {
+			e.out.Send("Illegal move: %s", strings.Join(args, " "))
+			return false
+		}
+		if e.sendResult() {
+			return false
+		}
+		if !e.force && e.board.GetSideToMove() == e.color {
+			e.think()
+		}
+	case "?":
+		// move now with the best move found so far
+		if e.stop != nil {
+			close(e.stop)
+			e.stop = nil
+		}
+	case "result":
+		e.abortSearch()
+		e.force = true
+	case "undo":
+		e.abortSearch()
+		e.board.Undo()
+	case "remove":
+		e.abortSearch()
+		e.board.Undo()
+		e.board.Undo()
+	case "memory":
+		e.abortSearch()
+		if len(args) > 0 {
+			if size, err := strconv.Atoi(args[0]); err == nil && size >= 1 && size <= transposition.MaxSize {
+				e.table = transposition.New(size)
+			}
+		}
+	case "level":
+		e.setLevel(args)
+	case "st":
+		if len(args) > 0 {
+			if s, err := strconv.ParseFloat(args[0], 64); err == nil {
+				e.moveTime = time.Duration(s * float64(time.Second))
+			}
+		}
+	case "sd":
+		if len(args) > 0 {
+			if d, err := strconv.Atoi(args[0]); err == nil {
+				e.depth = d
+			}
+		}
+	case "time":
+		if len(args) > 0 {
+			if cs, err := strconv.Atoi(args[0]); err == nil {
+				e.clock = time.Duration(cs) * 10 * time.Millisecond
+			}
+		}
+	case "post":
+		e.post = true
+	case "nopost":
+		e.post = false
+	case "ping":
+		e.out.Send("pong %s", strings.Join(args, " "))
+	case "quit":
+		return true
+	default:
+		e.out.Send("Error (unknown command): %s", fields[0])
+	}
+	return false
+}
+
+// Sets up a new game from the initial position, in which the engine plays
+// black without a depth limit
+func (e *engine) newGame() {
+	e.board.LoadFEN(board.StartFEN)
+	e.table.Clear()
+	e.color = board.Black
+	e.force = false
+	e.depth = 0
+}
+
+// Sets the conventional clock of the arguments of the level command:
+//
+//	<moves per session> <base time in minutes or minutes:seconds> <increment in seconds>
+func (e *engine) setLevel(args []string) {
+	if len(args) != 3 {
+		e.out.Send("Error (invalid level): %s", strings.Join(args, " "))
+		return
+	}
+	mps, err := strconv.Atoi(args[0])
+	if err != nil {
+		e.out.Send("Error (invalid level): %s", strings.Join(args, " "))
+		return
+	}
+	base, err := parseBaseTime(args[1])
+	if err != nil {
+		e.out.Send("Error (invalid level): %s", strings.Join(args, " "))
+		return
+	}
+	inc, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		e.out.Send("Error (invalid level): %s", strings.Join(args, " "))
+		return
+	}
+	e.movesPerSession = mps
+	// the host tells the time left with the time command before every move,
+	// until then the clock has the base time
+	e.clock = base
+	e.increment = time.Duration(inc * float64(time.Second))
+	// the st command and the level command are exclusive
+	e.moveTime = 0
+}
+
+// Returns the base time of the level command, written in minutes or in
+// minutes:seconds
+func parseBaseTime(s string) (time.Duration, error) {
+	minutes, seconds := s, "0"
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		minutes, seconds = s[:i], s[i+1:]
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil {
+		return 0, err
+	}
+	sec, err := strconv.Atoi(seconds)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(m)*time.Minute + time.Duration(sec)*time.Second, nil
+}
+
+// Starts searching the move of the engine, unless the game has ended. The move
+// is made once the search result is received in Run
+func (e *engine) think() {
+	if e.board.GetResult() != board.Ongoing {
+		return
+	}
+	limits := search.Limits{Depth: e.depth}
+	switch {
+	case e.moveTime > 0:
+		limits.MoveTime = e.moveTime
+	case e.clock > 0:
+		// moves left to make before the clock gets the base time again
+		movesToGo := 0
+		if e.movesPerSession > 0 {
+			movesToGo = e.movesPerSession - (e.board.GetFullmoveNumber()-1)%e.movesPerSession
+		}
+		limits.MoveTime = search.AllocateTime(e.clock, e.increment, movesToGo)
+	case e.depth == 0:
+		limits.MoveTime = defaultMoveTime
+	}
+	e.searching, e.stop = true, make(chan struct{})
+	limits.Stop = e.stop
+	limits.Table = e.table
+	if e.post {
+		limits.Progress = e.sendThinking
+	}
+	protocol.StartSearch(e.board, limits, func(r search.Result) {
+		e.results <- r
+	})
+}
+
+// Stops the search going on, if any, without making its move
+func (e *engine) abortSearch() {
+	if !e.searching {
+		return
+	}
+	if e.stop != nil {
+		close(e.stop)
+	}
+	<-e.results
+	e.searching, e.stop = false, nil
+}
+
+// Makes the move found by the engine
+func (e *engine) play(m board.Move) {
+	if err := e.board.Move(m); err != nil {
+		e.out.Send("Error (engine move): %s", err.Error())
+		return
+	}
+	e.out.Send("move %s", m)
+	e.sendResult()
+}
+
+// Writes the result of the game if it has ended. Returns true if it has
+func (e *engine) sendResult() bool {
+	switch e.board.GetResult() {
+	case board.Checkmate:
+		winner := "White"
+		if e.board.GetSideToMove() == board.White {
+			winner = "Black"
+		}
+		e.out.Send("%s {%s mates}", e.board.GetPGNResult(), winner)
+	case board.Stalemate:
+		e.out.Send("%s {Stalemate}", e.board.GetPGNResult())
+	default:
+		return false
+	}
+	return true
+}
+
+// Writes the result of an iteration of the search as a thinking output line:
+// depth, score, time in centiseconds, nodes and principal variation
+func (e *engine) sendThinking(r search.Result) {
+	score := r.Score
+	if moves, ok := search.MateIn(r.Score); ok {
+		if moves > 0 {
+			score = mateScore + moves
+		} else {
+			score = -mateScore + moves
+		}
+	}
+	e.out.Send("%d %d %d %d %s", r.Depth, score, r.Time.Milliseconds()/10, r.Nodes, protocol.FormatPV(r.PV))
+}
@@ -0,0 +1,259 @@
+package xboard
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A session runs the engine in another goroutine, sending it commands and
+// reading its answers as a host would
+type session struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan string
+	done  chan error
+	pings int
+}
+
+func newSession(t *testing.T) *session {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	s := &session{t: t, in: inW, lines: make(chan string, 1000), done: make(chan error, 1)}
+	go func() {
+		err := Run(inR, outW, 1)
+		outW.Close()
+		s.done <- err
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			s.lines <- scanner.Text()
+		}
+		close(s.lines)
+	}()
+	s.send("xboard")
+	s.send("protover 2")
+	s.expect("feature")
+	return s
+}
+
+// Sends the commands to the engine
+func (s *session) send(cmds ...string) {
+	s.t.Helper()
+	for _, cmd := range cmds {
+		if _, err := io.WriteString(s.in, cmd+"\n"); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+}
+
+// Reads the answers of the engine until one starts with prefix, and returns
+// it along with the lines read before it
+func (s *session) expect(prefix string) (line string, before []string) {
+	s.t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				s.t.Fatalf("engine stopped before writing %q, after %q", prefix, before)
+			}
+			if strings.HasPrefix(line, prefix) {
+				return line, before
+			}
+			before = append(before, line)
+		case <-timeout:
+			s.t.Fatalf("engine did not write %q, after %q", prefix, before)
+		}
+	}
+}
+
+// Waits until the engine has run every command sent, and returns what it
+// wrote in the meantime
+func (s *session) sync() []string {
+	s.t.Helper()
+	s.pings++
+	s.send("ping " + strconv.Itoa(s.pings))
+	_, before := s.expect("pong " + strconv.Itoa(s.pings))
+	return before
+}
+
+// Quits the engine and checks it ends without errors
+func (s *session) quit() {
+	s.t.Helper()
+	s.send("quit")
+	if err := <-s.done; err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	inR, inW := io.Pipe()
+	var out strings.Builder
+	done := make(chan error)
+	go func() { done <- Run(inR, &out, 1) }()
+	io.WriteString(inW, "xboard\nprotover 2\nquit\n")
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	features := strings.Fields(strings.TrimSpace(out.String()))
+	for _, want := range []string{"usermove=1", "setboard=1", "ping=1", "memory=1", "analyze=0", "draw=0", "done=1"} {
+		found := false
+		for _, f := range features {
+			found = found || f == want
+		}
+		if !found {
+			t.Errorf("got features %q, want %s", features, want)
+		}
+	}
+}
+
+func TestUsermove(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "sd 2", "usermove e2e4")
+	// the engine plays black
+	s.expect("move ")
+	s.send("usermove e2e5")
+	if line, _ := s.expect("Illegal move"); line != "Illegal move: e2e5" {
+		t.Errorf("got %q, want Illegal move: e2e5", line)
+	}
+	s.send("usermove")
+	s.expect("Illegal move")
+	s.quit()
+}
+
+func TestForceAndGo(t *testing.T) {
+	s := newSession(t)
+	// in force mode the engine only checks the moves
+	s.send("new", "sd 2", "force", "usermove e2e4", "usermove e7e5")
+	if out := s.sync(); len(out) != 0 {
+		t.Errorf("got %q in force mode, want nothing", out)
+	}
+	// go makes the engine play the side to move, white
+	s.send("go")
+	s.expect("move ")
+	// and keep playing it after the moves of the host
+	s.send("usermove a7a6")
+	s.expect("move ")
+	s.quit()
+}
+
+func TestTimeControls(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+	}{
+		{"depth", []string{"sd 1"}},
+		{"time per move", []string{"st 0.2"}},
+		{"conventional clock", []string{"level 40 0:10 0", "time 1000", "otim 1000"}},
+		{"incremental clock", []string{"level 0 1 1", "time 500"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSession(t)
+			s.send("new")
+			s.send(tt.commands...)
+			start := time.Now()
+			s.send("usermove e2e4")
+			s.expect("move ")
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("the engine took %v to move", elapsed)
+			}
+			s.quit()
+		})
+	}
+	s := newSession(t)
+	s.send("level 40 x 0")
+	s.expect("Error (invalid level)")
+	s.quit()
+}
+
+func TestMoveNow(t *testing.T) {
+	s := newSession(t)
+	// a long search, which ? ends with the best move found so far
+	s.send("new", "st 60", "usermove e2e4")
+	time.Sleep(100 * time.Millisecond)
+	s.send("?")
+	s.expect("move ")
+	// ? without a search going on does nothing
+	s.send("?")
+	if out := s.sync(); len(out) != 0 {
+		t.Errorf("got %q, want nothing", out)
+	}
+	s.quit()
+}
+
+func TestResult(t *testing.T) {
+	s := newSession(t)
+	// the engine writes the result of the game it ends
+	s.send("force", "setboard 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "sd 3", "go")
+	if line, _ := s.expect("move "); line != "move a1a8" {
+		t.Errorf("got %q, want move a1a8", line)
+	}
+	if line, _ := s.expect("1-0"); line != "1-0 {White mates}" {
+		t.Errorf("got %q, want 1-0 {White mates}", line)
+	}
+	// and the result of the game the host ends
+	s.send("new", "sd 2", "force", "usermove f2f3", "usermove e7e5", "usermove g2g4", "usermove d8h4")
+	if line, _ := s.expect("0-1"); line != "0-1 {Black mates}" {
+		t.Errorf("got %q, want 0-1 {Black mates}", line)
+	}
+	// after the result command the engine does not play any more
+	s.send("new", "sd 2", "result 1/2-1/2 {Draw}", "usermove e2e4")
+	if out := s.sync(); len(out) != 0 {
+		t.Errorf("got %q after the result, want nothing", out)
+	}
+	s.quit()
+}
+
+func TestUndoRemove(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "force", "usermove e2e4", "usermove e7e5")
+	// undo takes back the last move, so black moves again
+	s.send("undo", "usermove e7e6")
+	if out := s.sync(); len(out) != 0 {
+		t.Errorf("got %q, want every move accepted", out)
+	}
+	// remove takes back the last two moves, so white moves from the initial
+	// position
+	s.send("remove", "usermove e7e5")
+	if line, _ := s.expect("Illegal move"); line != "Illegal move: e7e5" {
+		t.Errorf("got %q, want Illegal move: e7e5", line)
+	}
+	s.send("usermove d2d4")
+	if out := s.sync(); len(out) != 0 {
+		t.Errorf("got %q, want every move accepted", out)
+	}
+	s.quit()
+}
+
+func TestSetboard(t *testing.T) {
+	s := newSession(t)
+	s.send("setboard 8/8/8/8/8/8/8/8 w - - 0 1")
+	s.expect("tellusererror")
+	s.send("foo")
+	if line, _ := s.expect("Error"); line != "Error (unknown command): foo" {
+		t.Errorf("got %q, want the unknown command", line)
+	}
+	s.quit()
+}
+
+func TestPost(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "sd 3", "post", "usermove e2e4")
+	_, thinking := s.expect("move ")
+	if len(thinking) != 3 {
+		t.Fatalf("got %q, want a line per depth", thinking)
+	}
+	for i, line := range thinking {
+		// depth, score, time, nodes and a move per ply
+		if fields := strings.Fields(line); len(fields) != 4+i+1 || fields[0] != string(rune('1'+i)) {
+			t.Errorf("got %q, want the thinking output of depth %d", line, i+1)
+		}
+	}
+	s.quit()
+}